@@ -9,7 +9,14 @@ import (
 func main() {
 	fmt.Println("Testing OAuth API...")
 
-	usage, err := oauth.FetchUsage()
+	_, provider, err := oauth.ResolveCredentials(oauth.Providers(oauth.Options{}))
+	if err != nil {
+		fmt.Printf("Error finding credentials: %v\n", err)
+		return
+	}
+	fmt.Printf("Credentials from: %s\n", provider)
+
+	usage, err := oauth.FetchUsage(oauth.Options{})
 	if err != nil {
 		fmt.Printf("Error fetching usage: %v\n", err)
 		return
@@ -19,4 +26,11 @@ func main() {
 	fmt.Printf("5-hour resets at: %s\n", usage.FiveHour.ResetsAt)
 	fmt.Printf("7-day usage: %.1f%%\n", usage.SevenDay.Utilization)
 	fmt.Printf("7-day resets at: %s\n", usage.SevenDay.ResetsAt)
+
+	for name, bucket := range usage.Buckets {
+		fmt.Printf("bucket %s: %.1f%% (resets at: %s)\n", name, bucket.Utilization, bucket.ResetsAt)
+	}
+	if usage.ExtraUsage != nil {
+		fmt.Printf("extra usage enabled: %v (%.1f%%)\n", usage.ExtraUsage.IsEnabled, usage.ExtraUsage.Utilization)
+	}
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/internal/git"
+	"github.com/huyhandes/cc-hud-go/internal/oauth"
+)
+
+// runCommand dispatches a subcommand and returns the process exit code
+func runCommand(args []string, cfg *config.Config, w io.Writer) int {
+	switch {
+	case len(args) == 2 && args[0] == "debug" && args[1] == "auth":
+		return debugAuth(cfg, w)
+	case len(args) == 3 && args[0] == "session" && args[1] == "commits":
+		return sessionCommits(cfg, args[2], time.Now(), w)
+	default:
+		fmt.Fprintf(w, "unknown command: %v\n\n", args)
+		printUsage()
+		return 2
+	}
+}
+
+// debugAuth reports every OAuth credential provider and which one is used
+func debugAuth(cfg *config.Config, w io.Writer) int {
+	providers := oauth.Providers(oauthOptions(cfg))
+
+	fmt.Fprintln(w, "OAuth credential providers (in order):")
+	used := ""
+	for _, provider := range providers {
+		creds, err := provider.Credentials()
+		if err != nil {
+			fmt.Fprintf(w, "  %-18s not found: %v\n", provider.Name(), err)
+			continue
+		}
+		fmt.Fprintf(w, "  %-18s ok (%s)\n", provider.Name(), describeCredentials(creds, time.Now()))
+		if used == "" {
+			used = provider.Name()
+		}
+	}
+
+	if used == "" {
+		fmt.Fprintln(w, "No credentials found; rate limit usage will not be fetched")
+		return 1
+	}
+	fmt.Fprintf(w, "Using: %s\n", used)
+	return 0
+}
+
+// tokenScheme is the prefix of Claude Code's OAuth access tokens
+const tokenScheme = "sk-ant-oat01-"
+
+// describeCredentials summarizes credentials without revealing the token
+func describeCredentials(creds *oauth.Credentials, now time.Time) string {
+	// Only the known scheme prefix is shown; any other token is secret throughout
+	desc := fmt.Sprintf("token of %d characters", len(creds.AccessToken))
+	if strings.HasPrefix(creds.AccessToken, tokenScheme) {
+		desc = fmt.Sprintf("token %s… (%d characters)", tokenScheme, len(creds.AccessToken))
+	}
+	if creds.RefreshToken != "" {
+		desc += ", refresh token"
+	}
+	switch {
+	case creds.ExpiresAt.IsZero():
+	case creds.ExpiresAt.Before(now):
+		desc += ", expired " + now.Sub(creds.ExpiresAt).Round(time.Minute).String() + " ago"
+	default:
+		desc += ", expires in " + creds.ExpiresAt.Sub(now).Round(time.Minute).String()
+	}
+	return desc
+}
+
+// sessionCommits lists the commits a session created in each repository it
+// worked in, refreshing them first
+func sessionCommits(cfg *config.Config, sessionID string, now time.Time, w io.Writer) int {
+	repos := git.LoadSession(sessionID)
+	if len(repos) == 0 {
+		fmt.Fprintf(w, "No git activity recorded for session %s\n", sessionID)
+		return 1
+	}
+
+	dirs := make([]string, 0, len(repos))
+	for dir := range repos {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	provider := gitProvider(cfg.Git.Backend, git.Options{})
+	for i, dir := range dirs {
+		start := repos[dir]
+		commits := start.Commits
+		if status, err := provider.Status(dir); err == nil {
+			if fresh, err := git.SessionCommits(provider, sessionID, dir, status.HeadOID, now); err == nil {
+				commits = fresh
+			}
+		}
+
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		since := "an empty repository"
+		if start.Head != "" {
+			since = shortID(start.Head)
+		}
+		fmt.Fprintf(w, "%s (since %s, %s)\n", dir, since, start.StartedAt.Local().Format("2006-01-02 15:04"))
+
+		var total git.LineStats
+		for _, commit := range commits {
+			fmt.Fprintf(w, "  %s  %s  +%d/-%d\n", shortID(commit.ID), commit.Subject, commit.Lines.Added, commit.Lines.Removed)
+			total.Added += commit.Lines.Added
+			total.Removed += commit.Lines.Removed
+		}
+		switch len(commits) {
+		case 0:
+			fmt.Fprintln(w, "  No commits")
+		case 1:
+			fmt.Fprintf(w, "  1 commit, +%d/-%d\n", total.Added, total.Removed)
+		default:
+			fmt.Fprintf(w, "  %d commits, +%d/-%d\n", len(commits), total.Added, total.Removed)
+		}
+	}
+	return 0
+}
+
+// shortID abbreviates a commit id like git does
+func shortID(id string) string {
+	return id[:min(len(id), 7)]
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/internal/git"
+	"github.com/huyhandes/cc-hud-go/internal/oauth"
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+func TestDebugAuth(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	t.Setenv(oauth.TokenEnv, "sk-ant-oat01-secret-value")
+
+	var out bytes.Buffer
+	if code := runCommand([]string{"debug", "auth"}, config.Default(), &out); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, out.String())
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "Using: env") {
+		t.Errorf("expected env provider to be used, got: %s", output)
+	}
+	if !strings.Contains(output, "credentials-file") {
+		t.Errorf("expected every provider to be listed, got: %s", output)
+	}
+	if strings.Contains(output, "secret-value") {
+		t.Errorf("token must not be printed in full, got: %s", output)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	if code := runCommand([]string{"bogus"}, config.Default(), &out); code == 0 {
+		t.Error("expected non-zero exit code for unknown command")
+	}
+}
+
+func TestDescribeCredentials(t *testing.T) {
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)
+	creds := &oauth.Credentials{
+		AccessToken:  "sk-ant-oat01-abcdef",
+		RefreshToken: "refresh",
+		ExpiresAt:    now.Add(90 * time.Minute),
+	}
+
+	got := describeCredentials(creds, now)
+	want := "token sk-ant-oat01-… (19 characters), refresh token, expires in 1h30m0s"
+	if got != want {
+		t.Errorf("describeCredentials() = %q, want %q", got, want)
+	}
+
+	// A token in another format shows nothing of itself
+	bare := &oauth.Credentials{AccessToken: "abcdefghijklmnopqrstuvwxyz"}
+	if got := describeCredentials(bare, now); got != "token of 26 characters" {
+		t.Errorf("describeCredentials() = %q, want only the length", got)
+	}
+}
+
+func TestSessionCommitsCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	dir := t.TempDir()
+	gitRun := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gitRun("init", "-q", "-b", "main")
+	write("README.md", "hello\n")
+	gitRun("add", "README.md")
+	gitRun("commit", "-q", "-m", "initial")
+
+	// The statusline records the session start
+	if _, err := git.SessionCommits(git.ExecProvider{}, "abc", dir, gitRun("rev-parse", "HEAD"), time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	gitRun("add", "main.go")
+	gitRun("commit", "-q", "-m", "Add main")
+
+	var out bytes.Buffer
+	if code := runCommand([]string{"session", "commits", "abc"}, config.Default(), &out); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, out.String())
+	}
+	for _, want := range []string{dir, "Add main", "+3/-0", "1 commit, +3/-0"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output, got: %s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if code := runCommand([]string{"session", "commits", "unknown"}, config.Default(), &out); code != 1 {
+		t.Errorf("expected exit code 1 for an unknown session, got %d", code)
+	}
+}
@@ -4,26 +4,47 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 )
 
 // Config holds all configuration options
 type Config struct {
-	Theme             string
-	Colors            map[string]string
-	Preset            string
-	LineLayout        string
-	PathLevels        int
-	SevenDayThreshold int
-	Display           DisplayConfig
-	Git               GitConfig
-	Tools             ToolsConfig
-	Tables            TableConfig
+	Theme                string
+	Colors               map[string]string
+	ColorProfile         string            // "truecolor", "256", "16" or "none" ("" or "auto" = detect)
+	IconSet              string            // "emoji", "nerdfont" or "ascii"
+	Icons                map[string]string // Per-icon overrides by semantic name
+	Preset               string
+	LineLayout           string
+	OutputFormat         string // "text" (the statusline), "json" or "env"
+	Width                int    // Columns each line must fit in (0 = $COLUMNS or the terminal size)
+	Separator            SeparatorConfig
+	PathLevels           int
+	SevenDayThreshold    int
+	SevenDayMode         string // "always" or "threshold" (only show at/above SevenDayThreshold)
+	LimitsThreshold      int    // Show extra rate limit buckets (e.g. per-model weekly) at/above this %
+	AutocompactThreshold int    // Context % that triggers auto-compact (0 = default)
+	Display              DisplayConfig
+	Git                  GitConfig
+	Tools                ToolsConfig
+	Tables               TableConfig
+	Budget               BudgetConfig
+	OAuth                OAuthConfig
+	Refresh              RefreshConfig
+}
+
+// SeparatorConfig controls how the segments of a line are joined
+type SeparatorConfig struct {
+	Style  string   // "bar", "powerline", "rounded" or "custom"
+	Custom string   // Placed between segments with the "custom" style
+	Lines  []string // Style per line of the multi-line layout ("" = Style)
 }
 
 type DisplayConfig struct {
 	Model      bool
 	Context    bool
+	Cache      bool
 	Git        bool
 	Tools      bool
 	Agents     bool
@@ -34,10 +55,27 @@ type DisplayConfig struct {
 }
 
 type GitConfig struct {
-	ShowBranch      bool
-	ShowDirty       bool
-	ShowAheadBehind bool
-	ShowFileStats   bool
+	ShowBranch          bool
+	ShowDirty           bool
+	ShowAheadBehind     bool
+	ShowFileStats       bool
+	ShowOperation       bool // Rebase/merge/cherry-pick/revert/bisect in progress
+	ShowConflicts       bool
+	ShowUntracked       bool
+	ShowStash           bool
+	ShowTag             bool
+	ShowLineStats       bool     // Lines added/removed in the working tree (git diff --numstat)
+	ShowBase            bool     // Commits and lines since the merge-base with BaseBranch
+	BaseBranch          string   // Branch to compare against (empty = main, then master)
+	Backend             string   // "exec" runs the git CLI, "native" reads .git directly
+	ShowWorktree        bool     // Linked worktree name next to the branch
+	ExtraRepos          []string // More repositories shown compactly (relative to the workspace)
+	RepoTimeout         int      // Milliseconds allowed per extra repository
+	ShowSessionCommits  bool     // Commits created since the session started
+	ShowLastCommit      bool     // Age of the last commit
+	ShowCommitAuthor    bool     // Author of the last commit (with ShowLastCommit)
+	CommitSubjectLength int      // Characters of the last commit's subject to show (0 = hide)
+	DirtyWarnMinutes    int      // Warn when dirty this long after the last commit (0 = never)
 }
 
 type ToolsConfig struct {
@@ -47,6 +85,38 @@ type ToolsConfig struct {
 	ShowMCP         bool
 }
 
+// BudgetConfig holds spend caps in USD across all sessions (0 = no cap)
+type BudgetConfig struct {
+	Daily   float64
+	Weekly  float64
+	Monthly float64
+}
+
+// Enabled reports whether any budget cap is configured
+func (b BudgetConfig) Enabled() bool {
+	return b.Daily > 0 || b.Weekly > 0 || b.Monthly > 0
+}
+
+// OAuthConfig controls fetching rate limit usage from the OAuth API
+type OAuthConfig struct {
+	CacheTTL          int               // Seconds to reuse a fetched usage response before refreshing
+	CredentialCommand string            // Shell command printing an access token or credentials JSON
+	BaseURL           string            // API base URL (empty = https://api.anthropic.com)
+	ProxyURL          string            // Proxy for API requests (empty = HTTPS_PROXY environment)
+	CAFile            string            // PEM bundle trusted in addition to the system roots
+	Timeout           int               // Seconds before an API request is abandoned
+	Headers           map[string]string // Extra headers sent with usage API requests
+	Offline           bool              // Never contact the API; show cached values
+	OfflineAfter      int               // Consecutive network failures before switching to offline mode (0 = never)
+	RefreshToken      bool              // Exchange Claude Code's refresh token when the access token expires; false opts out (the exchange may log Claude Code out if the server rotates it)
+}
+
+// RefreshConfig controls how slow sources (OAuth usage, git status) are refreshed
+type RefreshConfig struct {
+	Async  bool // Render from cache and refresh in a detached background process
+	GitTTL int  // Seconds to reuse cached git status before refreshing
+}
+
 type TableConfig struct {
 	ToolsThreshold   int `json:"toolsTableThreshold"`
 	TasksThreshold   int `json:"tasksTableThreshold"`
@@ -58,13 +128,20 @@ func Default() *Config {
 	return &Config{
 		Theme:             "macchiato",
 		Colors:            make(map[string]string),
+		IconSet:           "emoji",
+		OutputFormat:      "text",
+		Separator:         SeparatorConfig{Style: "bar"},
+		Icons:             make(map[string]string),
 		Preset:            "full",
 		LineLayout:        "expanded",
 		PathLevels:        2,
 		SevenDayThreshold: 80,
+		SevenDayMode:      "always",
+		LimitsThreshold:   50,
 		Display: DisplayConfig{
 			Model:      true,
 			Context:    true,
+			Cache:      false, // Efficiency already shown in the context view
 			Git:        true,
 			Tools:      true,
 			Agents:     true,
@@ -74,10 +151,25 @@ func Default() *Config {
 			FetchOAuth: true,
 		},
 		Git: GitConfig{
-			ShowBranch:      true,
-			ShowDirty:       true,
-			ShowAheadBehind: true,
-			ShowFileStats:   true,
+			ShowBranch:          true,
+			ShowDirty:           true,
+			ShowAheadBehind:     true,
+			ShowFileStats:       true,
+			ShowOperation:       true,
+			ShowConflicts:       true,
+			ShowUntracked:       true,
+			ShowStash:           true,
+			ShowTag:             false,
+			ShowLineStats:       true,
+			ShowBase:            false,
+			Backend:             "exec",
+			ShowWorktree:        true,
+			RepoTimeout:         500,
+			ShowSessionCommits:  true,
+			ShowLastCommit:      false,
+			ShowCommitAuthor:    true,
+			CommitSubjectLength: 40,
+			DirtyWarnMinutes:    60,
 		},
 		Tools: ToolsConfig{
 			GroupByCategory: true,
@@ -85,6 +177,16 @@ func Default() *Config {
 			ShowSkills:      true,
 			ShowMCP:         true,
 		},
+		OAuth: OAuthConfig{
+			CacheTTL:     60,
+			Timeout:      5,
+			OfflineAfter: 3,
+			RefreshToken: true,
+		},
+		Refresh: RefreshConfig{
+			Async:  true,
+			GitTTL: 5,
+		},
 		Tables: TableConfig{
 			ToolsThreshold:   999, // Always use lipgloss inline view
 			TasksThreshold:   999, // Always use lipgloss inline view
@@ -126,10 +228,101 @@ func (c *Config) Validate() error {
 		return errors.New("pathLevels must be between 1 and 3")
 	}
 
+	for _, style := range append([]string{c.Separator.Style}, c.Separator.Lines...) {
+		switch style {
+		case "", "bar", "powerline", "rounded":
+		case "custom":
+			if c.Separator.Custom == "" {
+				return errors.New("separator.custom must be set for the custom style")
+			}
+		default:
+			return errors.New("separator styles must be \"bar\", \"powerline\", \"rounded\" or \"custom\"")
+		}
+	}
+
+	switch c.IconSet {
+	case "", "emoji", "nerdfont", "ascii":
+	default:
+		return errors.New("iconSet must be \"emoji\", \"nerdfont\" or \"ascii\"")
+	}
+
+	switch c.ColorProfile {
+	case "", "auto", "truecolor", "256", "16", "none":
+	default:
+		return errors.New("colorProfile must be \"auto\", \"truecolor\", \"256\", \"16\" or \"none\"")
+	}
+
+	switch c.OutputFormat {
+	case "", "text", "json", "env":
+	default:
+		return errors.New("outputFormat must be \"text\", \"json\" or \"env\"")
+	}
+
+	if c.Width < 0 {
+		return errors.New("width must not be negative")
+	}
+
 	if c.SevenDayThreshold < 0 || c.SevenDayThreshold > 100 {
 		return errors.New("sevenDayThreshold must be between 0 and 100")
 	}
 
+	if c.OAuth.CacheTTL < 0 {
+		return errors.New("oauth.cacheTTL must not be negative")
+	}
+
+	if c.OAuth.Timeout < 0 {
+		return errors.New("oauth.timeout must not be negative")
+	}
+
+	if c.OAuth.OfflineAfter < 0 {
+		return errors.New("oauth.offlineAfter must not be negative")
+	}
+
+	for field, raw := range map[string]string{"oauth.baseURL": c.OAuth.BaseURL, "oauth.proxyURL": c.OAuth.ProxyURL} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s must be an absolute URL", field)
+		}
+	}
+
+	if c.Refresh.GitTTL < 0 {
+		return errors.New("refresh.gitTTL must not be negative")
+	}
+
+	switch c.Git.Backend {
+	case "", "exec", "native":
+	default:
+		return errors.New("git.backend must be \"exec\" or \"native\"")
+	}
+
+	if c.Git.RepoTimeout < 0 {
+		return errors.New("git.repoTimeout must not be negative")
+	}
+
+	if c.Git.CommitSubjectLength < 0 || c.Git.DirtyWarnMinutes < 0 {
+		return errors.New("git.commitSubjectLength and git.dirtyWarnMinutes must not be negative")
+	}
+
+	if c.LimitsThreshold < 0 || c.LimitsThreshold > 100 {
+		return errors.New("limitsThreshold must be between 0 and 100")
+	}
+
+	switch c.SevenDayMode {
+	case "", "always", "threshold":
+	default:
+		return errors.New("sevenDayMode must be \"always\" or \"threshold\"")
+	}
+
+	if c.AutocompactThreshold < 0 || c.AutocompactThreshold > 100 {
+		return errors.New("autocompactThreshold must be between 0 and 100")
+	}
+
+	if c.Budget.Daily < 0 || c.Budget.Weekly < 0 || c.Budget.Monthly < 0 {
+		return errors.New("budget caps must not be negative")
+	}
+
 	return nil
 }
 
@@ -119,6 +119,118 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown sevenDayMode",
+			cfg: &Config{
+				PathLevels:   2,
+				SevenDayMode: "sometimes",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative git ttl",
+			cfg: &Config{
+				PathLevels: 2,
+				Refresh:    RefreshConfig{GitTTL: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown git backend",
+			cfg: &Config{
+				PathLevels: 2,
+				Git:        GitConfig{Backend: "libgit2"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative repo timeout",
+			cfg: &Config{
+				PathLevels: 2,
+				Git:        GitConfig{RepoTimeout: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative dirty warning",
+			cfg: &Config{
+				PathLevels: 2,
+				Git:        GitConfig{DirtyWarnMinutes: -5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown separator style",
+			cfg: &Config{
+				PathLevels: 2,
+				Separator:  SeparatorConfig{Lines: []string{"bar", "zigzag"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom separator without string",
+			cfg: &Config{
+				PathLevels: 2,
+				Separator:  SeparatorConfig{Style: "custom"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown icon set",
+			cfg: &Config{
+				PathLevels: 2,
+				IconSet:    "wingdings",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown color profile",
+			cfg: &Config{
+				PathLevels:   2,
+				ColorProfile: "8bit",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown output format",
+			cfg: &Config{
+				PathLevels:   2,
+				OutputFormat: "yaml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative width",
+			cfg: &Config{
+				PathLevels: 2,
+				Width:      -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative oauth base url",
+			cfg: &Config{
+				PathLevels: 2,
+				OAuth:      OAuthConfig{BaseURL: "api.example.com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative oauth timeout",
+			cfg: &Config{
+				PathLevels: 2,
+				OAuth:      OAuthConfig{Timeout: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative offlineAfter",
+			cfg: &Config{
+				PathLevels: 2,
+				OAuth:      OAuthConfig{OfflineAfter: -1},
+			},
+			wantErr: true,
+		},
 		{
 			name: "threshold too high",
 			cfg: &Config{
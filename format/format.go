@@ -1,9 +1,23 @@
 package format
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
 
-// Tokens formats a token count for display (e.g. 5000 → "5k", 200000 → "200k", 500 → "500")
+// Tokens formats a token count for display (e.g. 5000 → "5k", 200000 → "200k", 500 → "500",
+// 1000000 → "1M", 1500000 → "1.5M")
 func Tokens(tokens int) string {
+	if tokens >= 1_000_000 {
+		millions := float64(tokens) / 1_000_000
+		// One decimal below 100M, dropping a trailing ".0"
+		if millions < 100 {
+			return strings.TrimSuffix(fmt.Sprintf("%.1f", math.Floor(millions*10)/10), ".0") + "M"
+		}
+		return fmt.Sprintf("%dM", tokens/1_000_000)
+	}
 	if tokens >= 1000 {
 		return fmt.Sprintf("%dk", tokens/1000)
 	}
@@ -26,3 +40,36 @@ func Duration(ms int64) string {
 func Cost(usd float64) string {
 	return fmt.Sprintf("$%.4f", usd)
 }
+
+// Countdown formats a remaining duration compactly (e.g. "2d5h", "1h59m", "45m")
+func Countdown(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// Age formats how long ago something happened (e.g. "just now", "45m ago", "3h ago", "2d ago")
+func Age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours())/24)
+	}
+}
@@ -1,6 +1,9 @@
 package format
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestTokens(t *testing.T) {
 	tests := []struct {
@@ -13,7 +16,13 @@ func TestTokens(t *testing.T) {
 		{1000, "1k"},
 		{5000, "5k"},
 		{200000, "200k"},
-		{1000000, "1000k"},
+		{999999, "999k"},
+		{1000000, "1M"},
+		{1050000, "1M"},
+		{1500000, "1.5M"},
+		{1234567, "1.2M"},
+		{12345678, "12.3M"},
+		{250000000, "250M"},
 	}
 
 	for _, tt := range tests {
@@ -63,3 +72,42 @@ func TestCost(t *testing.T) {
 		}
 	}
 }
+
+func TestCountdown(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-time.Minute, "0m"},
+		{45 * time.Minute, "45m"},
+		{2*time.Hour + 30*time.Minute, "2h30m"},
+		{24 * time.Hour, "1d0h"},
+		{5*24*time.Hour + 7*time.Hour + 59*time.Minute, "5d7h"},
+	}
+
+	for _, tt := range tests {
+		got := Countdown(tt.d)
+		if got != tt.want {
+			t.Errorf("Countdown(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-time.Second, "just now"},
+		{30 * time.Second, "just now"},
+		{45 * time.Minute, "45m ago"},
+		{3*time.Hour + 59*time.Minute, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+
+	for _, tt := range tests {
+		if got := Age(tt.d); got != tt.want {
+			t.Errorf("Age(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package icons
+
+// Set maps semantic icon names to the glyphs displayed for them
+type Set map[string]string
+
+// Emoji is the default set
+var Emoji = Set{
+	// Session
+	"model":       "🤖",
+	"context":     "🧠",
+	"contextSize": "⚡",
+	"input":       "📥",
+	"output":      "📤",
+	"cache":       "💾",
+	"cacheRatio":  "♻️",
+	"compaction":  "⏳",
+	"cost":        "💰",
+	"duration":    "⏱",
+	"budget":      "💳",
+	"fileChanges": "📝",
+	"agent":       "👤",
+
+	// Rate limits
+	"fiveHour":  "⏱️",
+	"sevenDay":  "📊",
+	"limits":    "🚦",
+	"onTrack":   "✓",
+	"warning":   "⚠",
+	"conflicts": "✖",
+
+	// Git
+	"branch":     "🌿",
+	"detached":   "➦",
+	"ahead":      "↑",
+	"behind":     "↓",
+	"renamed":    "»",
+	"lines":      "Δ",
+	"stash":      "≡",
+	"tag":        "🏷",
+	"lastCommit": "🕓",
+
+	// Tools and tasks
+	"tools":      "🔧",
+	"app":        "📦",
+	"mcp":        "🔌",
+	"skills":     "⚡",
+	"custom":     "🎨",
+	"tasks":      "📋",
+	"pending":    "⏳",
+	"inProgress": "🔄",
+	"completed":  "✅",
+}
+
+// NerdFont uses single-width glyphs from a patched Nerd Font
+var NerdFont = Set{
+	// Session
+	"model":       "", // nf-fa-microchip
+	"context":     "", // nf-fa-pie_chart
+	"contextSize": "", // nf-fa-bolt
+	"input":       "", // nf-fa-download
+	"output":      "", // nf-fa-upload
+	"cache":       "", // nf-fa-database
+	"cacheRatio":  "", // nf-fa-recycle
+	"compaction":  "", // nf-fa-hourglass_half
+	"cost":        "", // nf-fa-money
+	"duration":    "", // nf-fa-clock_o
+	"budget":      "", // nf-fa-credit_card
+	"fileChanges": "", // nf-fa-pencil
+	"agent":       "", // nf-fa-user
+
+	// Rate limits
+	"fiveHour":  "", // nf-fa-tachometer
+	"sevenDay":  "", // nf-fa-bar_chart
+	"limits":    "", // nf-fa-sliders
+	"onTrack":   "", // nf-fa-check
+	"warning":   "", // nf-fa-warning
+	"conflicts": "", // nf-fa-times
+
+	// Git
+	"branch":     "", // Powerline branch
+	"detached":   "", // nf-dev-git_commit
+	"ahead":      "", // nf-fa-arrow_up
+	"behind":     "", // nf-fa-arrow_down
+	"renamed":    "»",
+	"lines":      "Δ",
+	"stash":      "", // nf-fa-archive
+	"tag":        "", // nf-fa-tag
+	"lastCommit": "", // nf-fa-clock_o
+
+	// Tools and tasks
+	"tools":      "", // nf-fa-wrench
+	"app":        "", // nf-fa-cube
+	"mcp":        "", // nf-fa-plug
+	"skills":     "", // nf-fa-bolt
+	"custom":     "", // nf-fa-paint_brush
+	"tasks":      "", // nf-fa-tasks
+	"pending":    "", // nf-fa-hourglass_half
+	"inProgress": "", // nf-fa-spinner
+	"completed":  "", // nf-fa-check_circle
+}
+
+// ASCII works in any terminal and font
+var ASCII = Set{
+	// Session
+	"model":       "*",
+	"context":     "ctx",
+	"contextSize": "max",
+	"input":       "in",
+	"output":      "out",
+	"cache":       "cache",
+	"cacheRatio":  "cache",
+	"compaction":  "compact",
+	"cost":        "",
+	"duration":    "t",
+	"budget":      "budget",
+	"fileChanges": "diff",
+	"agent":       "@",
+
+	// Rate limits
+	"fiveHour":  "5h",
+	"sevenDay":  "7d",
+	"limits":    "limits",
+	"onTrack":   "ok",
+	"warning":   "!",
+	"conflicts": "x",
+
+	// Git
+	"branch":     "git",
+	"detached":   "git@",
+	"ahead":      "^",
+	"behind":     "v",
+	"renamed":    ">",
+	"lines":      "d",
+	"stash":      "=",
+	"tag":        "tag",
+	"lastCommit": "last",
+
+	// Tools and tasks
+	"tools":      "tools",
+	"app":        "-",
+	"mcp":        "-",
+	"skills":     "-",
+	"custom":     "-",
+	"tasks":      "tasks",
+	"pending":    "-",
+	"inProgress": ">",
+	"completed":  "+",
+}
+
+// current is the set segments render with
+var current = Emoji
+
+// GetSet returns an icon set by name, falls back to emoji
+func GetSet(name string) Set {
+	switch name {
+	case "nerdfont":
+		return NerdFont
+	case "ascii":
+		return ASCII
+	default:
+		return Emoji
+	}
+}
+
+// Init selects the icon set by name and applies per-icon overrides
+func Init(name string, overrides map[string]string) {
+	base := GetSet(name)
+	if len(overrides) == 0 {
+		current = base
+		return
+	}
+
+	current = make(Set, len(base))
+	for key, icon := range base {
+		current[key] = icon
+	}
+	for key, icon := range overrides {
+		current[key] = icon
+	}
+}
+
+// Get returns the icon for a semantic name, "" when unknown
+func Get(name string) string {
+	return current[name]
+}
+
+// Label prefixes text with the named icon and a space, or returns text alone
+// when the icon is empty
+func Label(name, text string) string {
+	icon := Get(name)
+	if icon == "" {
+		return text
+	}
+	if text == "" {
+		return icon
+	}
+	return icon + " " + text
+}
@@ -0,0 +1,74 @@
+package icons
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestSetsDefineSameIcons(t *testing.T) {
+	for name, set := range map[string]Set{"nerdfont": NerdFont, "ascii": ASCII} {
+		for key := range Emoji {
+			if _, ok := set[key]; !ok {
+				t.Errorf("%s set is missing %q", name, key)
+			}
+		}
+		for key := range set {
+			if _, ok := Emoji[key]; !ok {
+				t.Errorf("%s set has %q, which emoji lacks", name, key)
+			}
+		}
+	}
+}
+
+func TestSingleWidthSets(t *testing.T) {
+	for key, icon := range NerdFont {
+		if w := lipgloss.Width(icon); w > 1 {
+			t.Errorf("nerd font icon %q is %d cells wide", key, w)
+		}
+	}
+	for key, icon := range ASCII {
+		for _, r := range icon {
+			if r > 0x7e {
+				t.Errorf("ascii icon %q contains %q", key, r)
+			}
+		}
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	if GetSet("ascii")["model"] != ASCII["model"] {
+		t.Error("expected the ascii set")
+	}
+	if GetSet("nerdfont")["model"] != NerdFont["model"] {
+		t.Error("expected the nerd font set")
+	}
+	if GetSet("unknown")["model"] != Emoji["model"] {
+		t.Error("expected fallback to emoji")
+	}
+}
+
+func TestInitWithOverrides(t *testing.T) {
+	defer Init("emoji", nil)
+
+	Init("ascii", map[string]string{"model": "AI", "branch": ""})
+	if got := Get("model"); got != "AI" {
+		t.Errorf("expected override, got %q", got)
+	}
+	if got := Get("tools"); got != "tools" {
+		t.Errorf("expected ascii icon, got %q", got)
+	}
+	if ASCII["model"] != "*" {
+		t.Error("overrides must not modify the base set")
+	}
+
+	if got := Label("model", "Opus"); got != "AI Opus" {
+		t.Errorf("Label = %q", got)
+	}
+	if got := Label("branch", "main"); got != "main" {
+		t.Errorf("expected an empty icon to leave the text alone, got %q", got)
+	}
+	if got := Label("model", ""); got != "AI" {
+		t.Errorf("expected the icon alone, got %q", got)
+	}
+}
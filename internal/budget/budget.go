@@ -0,0 +1,166 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+// ledgerFile is the store file holding per-session spend
+const ledgerFile = "spend.json"
+
+// ledgerLock serializes updates to the ledger across concurrent sessions
+const ledgerLock = "spend.lock"
+
+// Updates hold the lock for milliseconds; one older than lockTTL was abandoned
+const (
+	lockTTL  = 5 * time.Second
+	lockWait = 500 * time.Millisecond
+)
+
+// retention is how long per-day spend is kept (long enough for a monthly total)
+const retention = 62 * 24 * time.Hour
+
+const dayLayout = "2006-01-02"
+
+// Ledger tracks cumulative spend per session, split by the local day it accrued on
+type Ledger struct {
+	Sessions map[string]*SessionSpend `json:"sessions"`
+}
+
+// SessionSpend holds the cost a single session accrued on each day
+type SessionSpend struct {
+	Days map[string]float64 `json:"days"`
+}
+
+// Totals holds spend aggregated across all sessions
+type Totals struct {
+	Daily   float64
+	Weekly  float64
+	Monthly float64
+}
+
+// Total returns the overall cost recorded for the session
+func (s *SessionSpend) Total() float64 {
+	total := 0.0
+	for _, usd := range s.Days {
+		total += usd
+	}
+	return total
+}
+
+// Record updates the ledger with the session's latest cumulative cost.
+// Any increase since the last record is attributed to the day of now, so a
+// session spanning midnight is split correctly between both days.
+func (l *Ledger) Record(sessionID string, totalUSD float64, now time.Time) {
+	if sessionID == "" || totalUSD <= 0 {
+		return
+	}
+
+	if l.Sessions == nil {
+		l.Sessions = make(map[string]*SessionSpend)
+	}
+
+	session := l.Sessions[sessionID]
+	if session == nil {
+		session = &SessionSpend{Days: make(map[string]float64)}
+		l.Sessions[sessionID] = session
+	}
+
+	if delta := totalUSD - session.Total(); delta > 0 {
+		session.Days[now.Format(dayLayout)] += delta
+	}
+}
+
+// Prune drops spend older than the retention window and empty sessions
+func (l *Ledger) Prune(now time.Time) {
+	cutoff := now.Add(-retention).Format(dayLayout)
+
+	for id, session := range l.Sessions {
+		for day := range session.Days {
+			if day < cutoff {
+				delete(session.Days, day)
+			}
+		}
+		if len(session.Days) == 0 {
+			delete(l.Sessions, id)
+		}
+	}
+}
+
+// Totals sums spend for the current day, week (starting Monday) and month
+func (l *Ledger) Totals(now time.Time) Totals {
+	today := now.Format(dayLayout)
+	weekStart := startOfWeek(now).Format(dayLayout)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format(dayLayout)
+
+	var totals Totals
+	for _, session := range l.Sessions {
+		for day, usd := range session.Days {
+			if day > today {
+				continue
+			}
+			if day == today {
+				totals.Daily += usd
+			}
+			if day >= weekStart {
+				totals.Weekly += usd
+			}
+			if day >= monthStart {
+				totals.Monthly += usd
+			}
+		}
+	}
+
+	return totals
+}
+
+// startOfWeek returns midnight of the Monday of now's week
+func startOfWeek(now time.Time) time.Time {
+	offset := (int(now.Weekday()) + 6) % 7 // Monday = 0
+	day := now.AddDate(0, 0, -offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// Load reads the ledger from the local store, returning an empty ledger if none exists
+func Load() (*Ledger, error) {
+	ledger := &Ledger{Sessions: make(map[string]*SessionSpend)}
+	if err := store.Load(ledgerFile, ledger); err != nil {
+		return &Ledger{Sessions: make(map[string]*SessionSpend)}, err
+	}
+	if ledger.Sessions == nil {
+		ledger.Sessions = make(map[string]*SessionSpend)
+	}
+	return ledger, nil
+}
+
+// Save writes the ledger to the local store
+func (l *Ledger) Save() error {
+	return store.Save(ledgerFile, l)
+}
+
+// Update records the session's cost in the persisted ledger and returns the
+// aggregated totals across all sessions. If another session holds the ledger
+// too long, the totals include this session's spend but it isn't saved; the
+// next render records it, since the cost is cumulative.
+func Update(sessionID string, totalUSD float64, now time.Time) (Totals, error) {
+	release, lockErr := store.Lock(ledgerLock, lockTTL, lockWait)
+	if lockErr == nil {
+		defer release()
+	}
+
+	ledger, err := Load()
+	if err != nil {
+		// Corrupt ledger: start over rather than failing the statusline
+		ledger = &Ledger{Sessions: make(map[string]*SessionSpend)}
+	}
+
+	ledger.Record(sessionID, totalUSD, now)
+	ledger.Prune(now)
+
+	totals := ledger.Totals(now)
+	if lockErr != nil {
+		return totals, lockErr
+	}
+	return totals, ledger.Save()
+}
@@ -0,0 +1,135 @@
+package budget
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRecordAccumulatesDeltas(t *testing.T) {
+	now := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC) // Wednesday
+	l := &Ledger{}
+
+	l.Record("a", 1.0, now)
+	l.Record("a", 2.5, now.Add(time.Hour))
+	l.Record("b", 4.0, now)
+
+	totals := l.Totals(now)
+	if !approx(totals.Daily, 6.5) {
+		t.Errorf("expected daily 6.5, got %f", totals.Daily)
+	}
+
+	// Re-recording the same cumulative cost must not double count
+	l.Record("a", 2.5, now.Add(2*time.Hour))
+	if got := l.Totals(now).Daily; !approx(got, 6.5) {
+		t.Errorf("expected daily to stay 6.5, got %f", got)
+	}
+}
+
+func TestRecordSplitsAcrossMidnight(t *testing.T) {
+	before := time.Date(2026, 3, 11, 23, 30, 0, 0, time.UTC)
+	after := time.Date(2026, 3, 12, 0, 30, 0, 0, time.UTC)
+	l := &Ledger{}
+
+	l.Record("a", 3.0, before)
+	l.Record("a", 5.0, after)
+
+	totals := l.Totals(after)
+	if !approx(totals.Daily, 2.0) {
+		t.Errorf("expected today's spend 2.0, got %f", totals.Daily)
+	}
+	if !approx(totals.Weekly, 5.0) {
+		t.Errorf("expected weekly spend 5.0, got %f", totals.Weekly)
+	}
+}
+
+func TestTotalsPeriods(t *testing.T) {
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC) // Wednesday
+	l := &Ledger{Sessions: map[string]*SessionSpend{
+		"a": {Days: map[string]float64{
+			"2026-03-11": 1, // today
+			"2026-03-09": 2, // Monday, same week
+			"2026-03-08": 4, // Sunday, previous week, same month
+			"2026-02-28": 8, // previous month
+		}},
+	}}
+
+	totals := l.Totals(now)
+	if !approx(totals.Daily, 1) {
+		t.Errorf("daily = %f, want 1", totals.Daily)
+	}
+	if !approx(totals.Weekly, 3) {
+		t.Errorf("weekly = %f, want 3", totals.Weekly)
+	}
+	if !approx(totals.Monthly, 7) {
+		t.Errorf("monthly = %f, want 7", totals.Monthly)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := &Ledger{Sessions: map[string]*SessionSpend{
+		"old": {Days: map[string]float64{"2026-01-01": 5}},
+		"new": {Days: map[string]float64{"2026-05-30": 1, "2026-01-02": 2}},
+	}}
+
+	l.Prune(now)
+
+	if _, ok := l.Sessions["old"]; ok {
+		t.Error("expected expired session to be pruned")
+	}
+	if len(l.Sessions["new"].Days) != 1 {
+		t.Errorf("expected only recent day kept, got %v", l.Sessions["new"].Days)
+	}
+}
+
+func TestUpdatePersists(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)
+
+	if _, err := Update("a", 1.5, now); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	totals, err := Update("b", 2.0, now)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if !approx(totals.Daily, 3.5) {
+		t.Errorf("expected daily total across sessions 3.5, got %f", totals.Daily)
+	}
+}
+
+func TestUpdateConcurrentSessions(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)
+
+	const sessions = 20
+	var wg sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := Update(fmt.Sprintf("session-%d", i), 1.0, now); err != nil {
+				t.Errorf("Update failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	ledger, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := len(ledger.Sessions); got != sessions {
+		t.Errorf("expected all %d sessions recorded, got %d", sessions, got)
+	}
+}
@@ -0,0 +1,140 @@
+package burndown
+
+import (
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+// historyFile is the store file holding recent five-hour utilization samples
+const historyFile = "fivehour.json"
+
+// historyLock serializes updates to the history across concurrent sessions
+const historyLock = "fivehour.lock"
+
+// Updates hold the lock for milliseconds; one older than lockTTL was abandoned
+const (
+	lockTTL  = 5 * time.Second
+	lockWait = 500 * time.Millisecond
+)
+
+const (
+	// maxSamples bounds the history file size
+	maxSamples = 120
+	// fitWindow is how far back samples are used to estimate the burn rate
+	fitWindow = 30 * time.Minute
+	// minSpan is the minimum time covered by samples before projecting
+	minSpan = 2 * time.Minute
+	// minInterval avoids recording a sample on every rapid refresh
+	minInterval = 15 * time.Second
+)
+
+// Sample is a single utilization reading
+type Sample struct {
+	Time    time.Time `json:"time"`
+	Percent float64   `json:"percent"`
+}
+
+// History holds samples for the current five-hour window
+type History struct {
+	ResetsAt string   `json:"resetsAt"`
+	Samples  []Sample `json:"samples"`
+}
+
+// Add records a reading, starting over when the window resets. It reports
+// whether a new sample was appended; rapid readings only refresh the last one.
+func (h *History) Add(percent float64, resetsAt string, now time.Time) bool {
+	if resetsAt != h.ResetsAt {
+		h.ResetsAt = resetsAt
+		h.Samples = nil
+	}
+
+	if n := len(h.Samples); n > 0 {
+		last := h.Samples[n-1]
+		// Utilization only grows within a window; a drop means it rolled over
+		if percent < last.Percent {
+			h.Samples = nil
+		} else if now.Sub(last.Time) < minInterval {
+			h.Samples[n-1] = Sample{Time: last.Time, Percent: percent}
+			return false
+		}
+	}
+
+	h.Samples = append(h.Samples, Sample{Time: now, Percent: percent})
+	if len(h.Samples) > maxSamples {
+		h.Samples = h.Samples[len(h.Samples)-maxSamples:]
+	}
+	return true
+}
+
+// Project fits a linear burn rate to recent samples and returns when
+// utilization is expected to reach 100%. Returns false when there is not
+// enough history or usage is not growing.
+func Project(samples []Sample, now time.Time) (time.Time, bool) {
+	cutoff := now.Add(-fitWindow)
+	recent := make([]Sample, 0, len(samples))
+	for _, sample := range samples {
+		if !sample.Time.Before(cutoff) {
+			recent = append(recent, sample)
+		}
+	}
+	if len(recent) < 2 || recent[len(recent)-1].Time.Sub(recent[0].Time) < minSpan {
+		return time.Time{}, false
+	}
+
+	// Least-squares slope in percent per second, relative to the first sample
+	origin := recent[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range recent {
+		x := sample.Time.Sub(origin).Seconds()
+		y := sample.Percent
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(recent))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return time.Time{}, false
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	if slope <= 0 {
+		return time.Time{}, false
+	}
+
+	last := recent[len(recent)-1]
+	if last.Percent >= 100 {
+		return last.Time, true
+	}
+
+	seconds := (100 - last.Percent) / slope
+	return last.Time.Add(time.Duration(seconds * float64(time.Second))), true
+}
+
+// Update records a reading taken at now in the persisted history and projects
+// exhaustion. The file is only rewritten when a sample is appended, so renders
+// of the same cached reading don't touch it.
+func Update(percent float64, resetsAt string, now time.Time) (time.Time, bool, error) {
+	release, lockErr := store.Lock(historyLock, lockTTL, lockWait)
+	if lockErr == nil {
+		defer release()
+	}
+
+	var history History
+	if err := store.Load(historyFile, &history); err != nil {
+		// Corrupt history: start over rather than failing the statusline
+		history = History{}
+	}
+
+	appended := history.Add(percent, resetsAt, now)
+	exhaustAt, ok := Project(history.Samples, now)
+
+	switch {
+	case lockErr != nil:
+		return exhaustAt, ok, lockErr
+	case !appended:
+		return exhaustAt, ok, nil
+	}
+	return exhaustAt, ok, store.Save(historyFile, &history)
+}
@@ -0,0 +1,156 @@
+package burndown
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+var base = time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)
+
+// series builds samples one minute apart from the given percentages
+func series(percents ...float64) []Sample {
+	samples := make([]Sample, len(percents))
+	for i, p := range percents {
+		samples[i] = Sample{Time: base.Add(time.Duration(i) * time.Minute), Percent: p}
+	}
+	return samples
+}
+
+func TestProjectLinearBurn(t *testing.T) {
+	// 1% per minute, currently at 50% -> 50 minutes to exhaustion
+	samples := series(46, 47, 48, 49, 50)
+	now := samples[len(samples)-1].Time
+
+	exhaustAt, ok := Project(samples, now)
+	if !ok {
+		t.Fatal("expected a projection")
+	}
+
+	if got := exhaustAt.Sub(now); got < 49*time.Minute || got > 51*time.Minute {
+		t.Errorf("expected ~50m to exhaustion, got %v", got)
+	}
+}
+
+func TestProjectNoisyBurn(t *testing.T) {
+	// Integer-rounded readings around 2% per minute
+	samples := series(10, 12, 13, 16, 18, 20)
+	now := samples[len(samples)-1].Time
+
+	exhaustAt, ok := Project(samples, now)
+	if !ok {
+		t.Fatal("expected a projection")
+	}
+
+	if got := exhaustAt.Sub(now); got < 35*time.Minute || got > 45*time.Minute {
+		t.Errorf("expected ~40m to exhaustion, got %v", got)
+	}
+}
+
+func TestProjectNotEnoughData(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Sample
+	}{
+		{"empty", nil},
+		{"single sample", series(20)},
+		{"span too short", []Sample{{Time: base, Percent: 10}, {Time: base.Add(30 * time.Second), Percent: 11}}},
+		{"flat usage", series(30, 30, 30, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Project(tt.samples, base.Add(10*time.Minute)); ok {
+				t.Error("expected no projection")
+			}
+		})
+	}
+}
+
+func TestProjectIgnoresStaleSamples(t *testing.T) {
+	// Fast burn an hour ago, flat since
+	samples := []Sample{
+		{Time: base, Percent: 10},
+		{Time: base.Add(10 * time.Minute), Percent: 40},
+		{Time: base.Add(60 * time.Minute), Percent: 40},
+		{Time: base.Add(70 * time.Minute), Percent: 40},
+	}
+
+	if _, ok := Project(samples, base.Add(70*time.Minute)); ok {
+		t.Error("expected stale burst outside the fit window to be ignored")
+	}
+}
+
+func TestHistoryAdd(t *testing.T) {
+	h := &History{}
+	h.Add(10, "reset-1", base)
+	h.Add(11, "reset-1", base.Add(5*time.Second)) // coalesced
+	h.Add(12, "reset-1", base.Add(time.Minute))
+
+	if len(h.Samples) != 2 {
+		t.Fatalf("expected rapid refreshes to coalesce, got %d samples", len(h.Samples))
+	}
+	if h.Samples[0].Percent != 11 {
+		t.Errorf("expected coalesced sample to keep latest value, got %v", h.Samples[0].Percent)
+	}
+
+	// New window starts over
+	h.Add(1, "reset-2", base.Add(2*time.Minute))
+	if len(h.Samples) != 1 || h.ResetsAt != "reset-2" {
+		t.Errorf("expected history reset on new window, got %+v", h)
+	}
+
+	// Utilization drop also starts over
+	h.Add(5, "reset-2", base.Add(3*time.Minute))
+	h.Add(2, "reset-2", base.Add(4*time.Minute))
+	if len(h.Samples) != 1 {
+		t.Errorf("expected history reset on utilization drop, got %d samples", len(h.Samples))
+	}
+}
+
+func TestUpdatePersists(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	for i, p := range []float64{40, 42, 44, 46} {
+		if _, _, err := Update(p, "reset", base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+
+	exhaustAt, ok, err := Update(48, "reset", base.Add(4*time.Minute))
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected projection from persisted history")
+	}
+	if got := exhaustAt.Sub(base.Add(4 * time.Minute)); got < 25*time.Minute || got > 27*time.Minute {
+		t.Errorf("expected ~26m to exhaustion, got %v", got)
+	}
+}
+
+func TestUpdateWritesOnlyNewSamples(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	if _, _, err := Update(40, "reset", base); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	path, err := store.Path(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := base.Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rendering the same cached reading again leaves the file alone
+	if _, _, err := Update(40, "reset", base); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("expected the history to stay untouched, got %v (%v)", info.ModTime(), err)
+	}
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+// cacheFile is the store file holding git info per working directory
+const cacheFile = "git-cache.json"
+
+// cacheLock serializes cache updates from refreshes of different workspaces
+const cacheLock = "git-cache.lock"
+
+// cacheRetention drops entries for directories not seen in a while
+const cacheRetention = 24 * time.Hour
+
+// CacheEntry is the cached git info for one working directory
+type CacheEntry struct {
+	Branch    string    `json:"branch"`
+	Status    *Status   `json:"status,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	TimedOut  bool      `json:"timedOut,omitempty"` // The last refresh gave up; Branch and Status are older
+}
+
+// Fresh reports whether the entry is younger than ttl
+func (e *CacheEntry) Fresh(ttl time.Duration, now time.Time) bool {
+	return now.Sub(e.FetchedAt) < ttl
+}
+
+// LoadCache returns the cached git info for dir, if any
+func LoadCache(dir string) (*CacheEntry, bool) {
+	entries := map[string]*CacheEntry{}
+	if err := store.Load(cacheFile, &entries); err != nil {
+		return nil, false
+	}
+
+	entry, ok := entries[dir]
+	if !ok || entry == nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Collect gathers git info for each directory concurrently. A directory that
+// takes longer than timeout (0 = no limit) gets an empty entry marked TimedOut.
+func Collect(provider Provider, dirs []string, timeout time.Duration, now time.Time) map[string]*CacheEntry {
+	type result struct {
+		dir   string
+		entry *CacheEntry
+	}
+	results := make(chan result, len(dirs))
+
+	for _, dir := range dirs {
+		go func() {
+			done := make(chan *CacheEntry, 1)
+			go func() { done <- collect(provider, dir, now) }()
+
+			var expired <-chan time.Time
+			if timeout > 0 {
+				timer := time.NewTimer(timeout)
+				defer timer.Stop()
+				expired = timer.C
+			}
+
+			select {
+			case entry := <-done:
+				results <- result{dir, entry}
+			case <-expired:
+				// The provider keeps running until its own git timeout; its result is dropped
+				results <- result{dir, &CacheEntry{FetchedAt: now, TimedOut: true}}
+			}
+		}()
+	}
+
+	entries := make(map[string]*CacheEntry, len(dirs))
+	for range dirs {
+		r := <-results
+		entries[r.dir] = r.entry
+	}
+	return entries
+}
+
+// collect reads git info for the repository containing dir
+func collect(provider Provider, dir string, now time.Time) *CacheEntry {
+	entry := &CacheEntry{FetchedAt: now}
+	if branch, err := provider.Branch(dir); err == nil {
+		entry.Branch = branch
+	}
+	if status, err := provider.Status(dir); err == nil {
+		entry.Status = status
+	}
+	return entry
+}
+
+// RefreshCache collects git info for each of dirs using provider, as Collect
+// does, and stores it under each dir. A timed out directory keeps its last
+// known info.
+func RefreshCache(provider Provider, dirs []string, timeout time.Duration, now time.Time) (map[string]*CacheEntry, error) {
+	collected := Collect(provider, dirs, timeout, now)
+
+	// Collecting is slow, so only the merge into the shared file is locked
+	release, err := store.Lock(cacheLock, lockTTL, lockWait)
+	if err != nil {
+		return collected, err
+	}
+	defer release()
+
+	entries := map[string]*CacheEntry{}
+	if err := store.Load(cacheFile, &entries); err != nil {
+		// Corrupt cache: start over
+		entries = map[string]*CacheEntry{}
+	}
+
+	for key, old := range entries {
+		if old == nil || now.Sub(old.FetchedAt) > cacheRetention {
+			delete(entries, key)
+		}
+	}
+	for dir, entry := range collected {
+		if old := entries[dir]; entry.TimedOut && old != nil {
+			entry.Branch = old.Branch
+			entry.Status = old.Status
+		}
+		entries[dir] = entry
+	}
+
+	return collected, store.Save(cacheFile, entries)
+}
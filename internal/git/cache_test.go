@@ -0,0 +1,132 @@
+package git
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+func TestRefreshCache(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := LoadCache(dir); ok {
+		t.Fatal("expected empty cache")
+	}
+
+	now := time.Now()
+	if _, err := RefreshCache(ExecProvider{}, []string{dir}, 0, now); err != nil {
+		t.Fatalf("RefreshCache failed: %v", err)
+	}
+
+	entry, ok := LoadCache(dir)
+	if !ok {
+		t.Fatal("expected cached entry after refresh")
+	}
+	if !entry.FetchedAt.Equal(now) {
+		t.Errorf("expected FetchedAt %v, got %v", now, entry.FetchedAt)
+	}
+	if !entry.Fresh(5*time.Second, now.Add(time.Second)) {
+		t.Error("expected entry to be fresh within ttl")
+	}
+	if entry.Fresh(5*time.Second, now.Add(10*time.Second)) {
+		t.Error("expected entry to be stale after ttl")
+	}
+
+	if _, ok := LoadCache("/some/other/dir"); ok {
+		t.Error("expected no entry for a different directory")
+	}
+}
+
+func TestRefreshCachePrunesOldEntries(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	old := time.Now().Add(-2 * cacheRetention)
+	if _, err := RefreshCache(ExecProvider{}, []string{"/old"}, 0, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RefreshCache(ExecProvider{}, []string{"/new"}, 0, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := LoadCache("/old"); ok {
+		t.Error("expected old entry to be pruned")
+	}
+	if _, ok := LoadCache("/new"); !ok {
+		t.Error("expected new entry to be kept")
+	}
+}
+
+// slowProvider blocks on one directory to exercise timeouts
+type slowProvider struct {
+	slow    string
+	release chan struct{}
+}
+
+func (p slowProvider) Branch(dir string) (string, error) {
+	if dir == p.slow {
+		<-p.release
+	}
+	return "branch-of-" + dir, nil
+}
+
+func (p slowProvider) Status(dir string) (*Status, error) {
+	return &Status{DirtyFiles: len(dir)}, nil
+}
+
+func (p slowProvider) Commits(dir, since string) ([]Commit, error) {
+	return nil, nil
+}
+
+func TestCollectTimesOutPerDirectory(t *testing.T) {
+	provider := slowProvider{slow: "/slow", release: make(chan struct{})}
+	defer close(provider.release)
+
+	start := time.Now()
+	entries := Collect(provider, []string{"/a", "/slow", "/bb"}, 50*time.Millisecond, start)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected collection bounded by timeout, took %v", elapsed)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if e := entries["/a"]; e.TimedOut || e.Branch != "branch-of-/a" || e.Status.DirtyFiles != 2 {
+		t.Errorf("unexpected entry for /a: %+v", e)
+	}
+	if e := entries["/bb"]; e.TimedOut || e.Status.DirtyFiles != 3 {
+		t.Errorf("unexpected entry for /bb: %+v", e)
+	}
+	if e := entries["/slow"]; !e.TimedOut || e.Branch != "" {
+		t.Errorf("expected /slow to time out, got %+v", e)
+	}
+}
+
+func TestRefreshCacheKeepsInfoOnTimeout(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	provider := slowProvider{slow: "/slow", release: make(chan struct{})}
+	defer close(provider.release)
+
+	// First refresh succeeds (not slow yet), the second times out
+	if _, err := RefreshCache(slowProvider{}, []string{"/slow"}, 0, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RefreshCache(provider, []string{"/slow"}, 20*time.Millisecond, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok := LoadCache("/slow")
+	if !ok {
+		t.Fatal("expected cached entry")
+	}
+	if !entry.TimedOut || entry.Branch != "branch-of-/slow" {
+		t.Errorf("expected previous info marked timed out, got %+v", entry)
+	}
+}
@@ -0,0 +1,335 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LineStats counts added and removed lines, like git diff --numstat
+type LineStats struct {
+	Added   int
+	Removed int
+}
+
+// IsZero reports whether no lines changed
+func (l LineStats) IsZero() bool {
+	return l.Added == 0 && l.Removed == 0
+}
+
+// add accumulates another set of counts
+func (l *LineStats) add(other LineStats) {
+	l.Added += other.Added
+	l.Removed += other.Removed
+}
+
+// parseNumstat sums `git diff --numstat` output; binary files ("-\t-\tpath") are skipped
+func parseNumstat(out string) LineStats {
+	var stats LineStats
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		added, errAdded := strconv.Atoi(fields[0])
+		removed, errRemoved := strconv.Atoi(fields[1])
+		if errAdded != nil || errRemoved != nil {
+			continue
+		}
+		stats.Added += added
+		stats.Removed += removed
+	}
+	return stats
+}
+
+// binaryCheckBytes is how much of a file git inspects for NUL bytes
+const binaryCheckBytes = 8000
+
+// isBinary applies git's heuristic: a NUL byte near the start means binary
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binaryCheckBytes)], 0) >= 0
+}
+
+// maxEditCost bounds the diff search on huge rewrites; beyond it lines are
+// matched without regard to order, which slightly undercounts
+const maxEditCost = 4096
+
+// diffLines counts the lines added and removed between two versions of a
+// file. Binary content counts as no change, as numstat leaves it out.
+func diffLines(before, after []byte) LineStats {
+	if bytes.Equal(before, after) || isBinary(before) || isBinary(after) {
+		return LineStats{}
+	}
+
+	a, b := splitLines(before), splitLines(after)
+
+	// Common prefix and suffix never count
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	cost, ok := editDistance(a, b, maxEditCost)
+	if !ok {
+		cost = unmatchedLines(a, b)
+	}
+	// Every edit is an insertion or a deletion, and insertions minus deletions
+	// is the change in length
+	grown := len(b) - len(a)
+	return LineStats{Added: (cost + grown) / 2, Removed: (cost - grown) / 2}
+}
+
+// splitLines splits content into lines, keeping terminators so that a
+// missing final newline counts as a change
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editDistance returns the minimum number of line insertions and deletions
+// turning a into b (Myers' algorithm), or false when it exceeds limit
+func editDistance(a, b []string, limit int) (int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return n + m, true
+	}
+
+	bound := min(n+m, limit)
+	offset := bound + 1
+	v := make([]int, 2*bound+3)
+	for d := 0; d <= bound; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return d, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// unmatchedLines approximates the edit distance by pairing equal lines regardless of order
+func unmatchedLines(a, b []string) int {
+	counts := map[string]int{}
+	for _, line := range a {
+		counts[line]++
+	}
+	matched := 0
+	for _, line := range b {
+		if counts[line] > 0 {
+			counts[line]--
+			matched++
+		}
+	}
+	return len(a) + len(b) - 2*matched
+}
+
+// blob returns a blob's content, or nil for an empty id or an unreadable object
+func (s *objectStore) blob(oid string) []byte {
+	if oid == "" {
+		return nil
+	}
+	obj, err := s.read(oid)
+	if err != nil || obj.kind != objBlob {
+		return nil
+	}
+	return obj.data
+}
+
+// worktreeContent reads a worktree file, or a symlink's target, nil when missing
+func (r *repo) worktreeContent(path string) []byte {
+	full := filepath.Join(r.workTree, filepath.FromSlash(path))
+	info, err := os.Lstat(full)
+	if err != nil {
+		return nil
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(full)
+		if err != nil {
+			return nil
+		}
+		return []byte(target)
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// tallyLines adds one status entry's line changes to the staged, unstaged
+// and total counts, matching git diff --cached, git diff and git diff HEAD
+func (r *repo) tallyLines(store *objectStore, c change, status *Status) {
+	if c.kind == 'u' {
+		// Unmerged paths compare our side with the conflicted worktree file
+		worktree := r.worktreeContent(c.path)
+		status.Unstaged.add(diffLines(store.blob(c.index), worktree))
+		status.Total.add(diffLines(store.blob(c.head), worktree))
+		return
+	}
+
+	x, y := c.xy[0], c.xy[1]
+	head := store.blob(c.head)
+	index := store.blob(c.index)
+	if y == 'A' {
+		index = nil // Intent-to-add entries hold a placeholder blob
+	}
+
+	after := index
+	if y != '.' {
+		after = r.worktreeContent(c.path)
+		if y == 'D' {
+			after = nil
+		}
+		status.Unstaged.add(diffLines(index, after))
+	}
+	if x != '.' {
+		status.Staged.add(diffLines(head, index))
+	}
+	status.Total.add(diffLines(head, after))
+}
+
+// resolveRevision finds a branch, remote branch or tag by short name, in
+// git's lookup order, and peels it to a commit
+func (r *repo) resolveRevision(store *objectStore, name string) (string, error) {
+	for _, ref := range []string{name, "refs/" + name, "refs/tags/" + name, "refs/heads/" + name, "refs/remotes/" + name, "refs/remotes/" + name + "/HEAD"} {
+		if !strings.HasPrefix(ref, "refs/") {
+			continue
+		}
+		if oid, err := r.resolveRef(ref); err == nil {
+			return store.peel(oid)
+		}
+	}
+	return "", fmt.Errorf("revision %s not found", name)
+}
+
+// compareBase fills the Base fields with the commits and lines on head since
+// its merge-base with branch (main or master when empty)
+func (r *repo) compareBase(ctx context.Context, store *objectStore, head, branch string, status *Status) error {
+	candidates := baseCandidates
+	if branch != "" {
+		candidates = []string{branch}
+	}
+	for _, name := range candidates {
+		base, err := r.resolveRevision(store, name)
+		if err != nil {
+			continue
+		}
+
+		mergeBase := mergeBase(ctx, store, head, base)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if mergeBase == "" {
+			return fmt.Errorf("no common history with %s", name)
+		}
+		commits, _, err := aheadBehind(ctx, store, head, mergeBase)
+		if err != nil {
+			return err
+		}
+		status.BaseCommits = commits
+
+		trees := [2]map[string]treeEntry{{}, {}}
+		for i, oid := range []string{mergeBase, head} {
+			commit, err := store.readCommit(oid)
+			if err != nil {
+				return err
+			}
+			if err := store.flattenTree(commit.tree, "", trees[i]); err != nil {
+				return err
+			}
+		}
+		status.BaseLines = diffTrees(store, trees[0], trees[1])
+		status.Base = name
+		return nil
+	}
+	return fmt.Errorf("base branch %q not found", strings.Join(candidates, "/"))
+}
+
+// diffTrees counts the lines changed between two flattened trees, pairing
+// exact renames like git diff does
+func diffTrees(store *objectStore, before, after map[string]treeEntry) LineStats {
+	var stats LineStats
+
+	deleted := map[string]int{} // Object id → number of removed paths with that content
+	for path, old := range before {
+		if _, ok := after[path]; !ok {
+			deleted[old.oid]++
+		}
+	}
+
+	for path, entry := range after {
+		old, ok := before[path]
+		switch {
+		case ok && old.oid != entry.oid:
+			stats.add(diffLines(store.blob(old.oid), store.blob(entry.oid)))
+		case !ok && deleted[entry.oid] > 0:
+			deleted[entry.oid]-- // Renamed without changes
+		case !ok:
+			stats.add(diffLines(nil, store.blob(entry.oid)))
+		}
+	}
+
+	for oid, count := range deleted {
+		removed := diffLines(store.blob(oid), nil)
+		stats.Removed += removed.Removed * count
+	}
+	return stats
+}
+
+// mergeBase finds the newest commit reachable from both a and b. Both sides
+// are walked together, newest first, so it stops at the fork point instead
+// of visiting all of b's history first.
+func mergeBase(ctx context.Context, store *objectStore, a, b string) string {
+	if a == b {
+		return a
+	}
+
+	const fromA, fromB = 1, 2
+	flags := map[string]int{a: fromA, b: fromB}
+	walk := newHistoryWalk(ctx, store)
+	walk.push(a)
+	walk.push(b)
+
+	visited := map[string]bool{}
+	for steps := 0; walk.more(steps); steps++ {
+		oid, parents := walk.pop()
+		if flags[oid] == fromA|fromB {
+			return oid
+		}
+		visited[oid] = true
+		for _, parent := range parents {
+			before := flags[parent]
+			flags[parent] |= flags[oid]
+			if visited[parent] && flags[parent] != before {
+				walk.requeue(parent)
+			} else {
+				walk.push(parent)
+			}
+		}
+	}
+	return ""
+}
@@ -3,18 +3,23 @@ package git
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// GetBranch returns the current git branch name
-func GetBranch() (string, error) {
+// GetBranch returns the current git branch name of the repository containing dir
+// (the process working directory when dir is empty)
+func GetBranch(dir string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
 	var out bytes.Buffer
 	cmd.Stdout = &out
 
@@ -33,56 +38,315 @@ type Status struct {
 	Added      int
 	Modified   int
 	Deleted    int
+	Renamed    int
+	Untracked  int
+	Conflicts  int
+	Stashes    int
+	Detached   bool   // HEAD is not on a branch
+	Head       string // Short SHA of HEAD, empty before the first commit
+	HeadOID    string // Full id of HEAD, empty before the first commit
+	Operation  string // "rebase", "am", "merge", "cherry-pick", "revert", "bisect" or empty
+	Tag        string // Nearest tag reachable from HEAD
+	Worktree   string // Linked worktree name, empty in the main worktree
+
+	LastCommitTime    time.Time // Committer time of HEAD
+	LastCommitAuthor  string
+	LastCommitSubject string
+
+	Unstaged    LineStats // Worktree vs index
+	Staged      LineStats // Index vs HEAD
+	Total       LineStats // Worktree vs HEAD
+	Base        string    // Branch compared against, empty when not compared
+	BaseCommits int       // Commits on HEAD since the merge-base with Base
+	BaseLines   LineStats // Lines changed between the merge-base and HEAD
 }
 
-// GetStatus returns git status information
-func GetStatus() (*Status, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel()
+// shortSHALength matches git's default abbreviation
+const shortSHALength = 7
 
-	status := &Status{}
+// commandTimeout bounds each git command. Optional commands get their own
+// budget, so a slow one can't starve the others or git status itself.
+const commandTimeout = 1 * time.Second
 
-	// Get ahead/behind
-	cmd := exec.CommandContext(ctx, "git", "rev-list", "--left-right", "--count", "HEAD...@{upstream}")
-	var out bytes.Buffer
-	cmd.Stdout = &out
+// GetStatus returns git status information for the repository containing dir
+// (the process working directory when dir is empty), running the commands
+// for optional information only when opts asks for it
+func GetStatus(dir string, opts Options) (*Status, error) {
+	out, err := runGitTimeout(dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return nil, err
+	}
+	status := parsePorcelainV2(out)
+
+	if gitDir, err := runGitTimeout(dir, "rev-parse", "--absolute-git-dir"); err == nil {
+		status.Operation = detectOperation(strings.TrimSpace(gitDir))
+		status.Worktree = worktreeName(strings.TrimSpace(gitDir))
+	}
+
+	// Fails when there is no stash, which means zero
+	if opts.Stash {
+		if out, err := runGitTimeout(dir, "rev-list", "--walk-reflogs", "--count", "refs/stash"); err == nil {
+			status.Stashes, _ = strconv.Atoi(strings.TrimSpace(out))
+		}
+	}
+
+	if status.Head != "" && opts.lastCommit(status.DirtyFiles) {
+		if out, err := runGitTimeout(dir, "log", "-1", "--format=%ct%x00%an%x00%s"); err == nil {
+			parseLastCommit(status, strings.TrimSuffix(out, "\n"))
+		}
+	}
+
+	// Fails when no tag is reachable
+	if opts.Tag {
+		if out, err := runGitTimeout(dir, "describe", "--tags", "--abbrev=0"); err == nil {
+			status.Tag = strings.TrimSpace(out)
+		}
+	}
+
+	if opts.LineStats {
+		if out, err := runGitTimeout(dir, "diff", "--numstat"); err == nil {
+			status.Unstaged = parseNumstat(out)
+		}
+		if out, err := runGitTimeout(dir, "diff", "--cached", "--numstat"); err == nil {
+			status.Staged = parseNumstat(out)
+		}
+		// Before the first commit everything is compared with the empty tree
+		head := "HEAD"
+		if status.Head == "" {
+			head = emptyTree
+		}
+		if out, err := runGitTimeout(dir, "diff", "--numstat", head); err == nil {
+			status.Total = parseNumstat(out)
+		}
+	}
+
+	return status, nil
+}
+
+// emptyTree is the id of the tree with no entries
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// baseCandidates are tried in order when no base branch is configured
+var baseCandidates = []string{"main", "master", "origin/main", "origin/master"}
+
+// compareBase fills the Base fields with the commits and lines on HEAD since
+// its merge-base with branch (main or master when empty). Each git command
+// gets its own budget, so a large base diff isn't starved by the others.
+func compareBase(dir, branch string, status *Status) error {
+	candidates := baseCandidates
+	if branch != "" {
+		candidates = []string{branch}
+	}
+	for _, name := range candidates {
+		if _, err := runGitTimeout(dir, "rev-parse", "--verify", "--quiet", name+"^{commit}"); err != nil {
+			continue
+		}
+
+		out, err := runGitTimeout(dir, "merge-base", "HEAD", name)
+		if err != nil {
+			return err
+		}
+		mergeBase := strings.TrimSpace(out)
+
+		out, err = runGitTimeout(dir, "rev-list", "--count", mergeBase+"..HEAD")
+		if err != nil {
+			return err
+		}
+		status.BaseCommits, _ = strconv.Atoi(strings.TrimSpace(out))
+
+		out, err = runGitTimeout(dir, "diff", "--numstat", mergeBase, "HEAD")
+		if err != nil {
+			return err
+		}
+		status.BaseLines = parseNumstat(out)
+		status.Base = name
+		return nil
+	}
+	return fmt.Errorf("base branch %q not found", strings.Join(candidates, "/"))
+}
+
+const (
+	// maxCommits bounds how many commits GetCommits and the native backend list
+	maxCommits = 200
+	// commitsTimeout bounds listing them, which also counts their lines
+	commitsTimeout = 2 * time.Second
+)
 
-	if err := cmd.Run(); err == nil {
-		parts := strings.Fields(out.String())
-		if len(parts) == 2 {
-			status.Ahead, _ = strconv.Atoi(parts[0])
-			status.Behind, _ = strconv.Atoi(parts[1])
+// GetCommits lists the commits reachable from HEAD but not from the commit
+// id since (all of HEAD's history when empty), newest first, by running git log
+func GetCommits(dir, since string) ([]Commit, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commitsTimeout)
+	defer cancel()
+
+	revision := "HEAD"
+	if since != "" {
+		revision = since + "..HEAD"
+	}
+	out, err := runGit(ctx, dir, "log", fmt.Sprintf("--max-count=%d", maxCommits), "--format=%x00%H %ct %s", "--numstat", revision)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(out), nil
+}
+
+// parseLog parses GetCommits' git log output: a NUL-prefixed
+// "<id> <committer time> <subject>" line per commit, followed by its numstat
+func parseLog(out string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x00")[1:] {
+		header, numstat, _ := strings.Cut(record, "\n")
+		fields := strings.SplitN(header, " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(fields[1], 10, 64)
+		commit := Commit{ID: fields[0], Time: time.Unix(seconds, 0), Lines: parseNumstat(numstat)}
+		if len(fields) == 3 {
+			commit.Subject = fields[2]
 		}
+		commits = append(commits, commit)
 	}
+	return commits
+}
 
-	// Get file stats
-	cmd = exec.CommandContext(ctx, "git", "status", "--porcelain")
-	out.Reset()
+// parseLastCommit reads `git log -1 --format=%ct%x00%an%x00%s` output
+func parseLastCommit(status *Status, out string) {
+	fields := strings.SplitN(out, "\x00", 3)
+	if len(fields) != 3 {
+		return
+	}
+	if seconds, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+		status.LastCommitTime = time.Unix(seconds, 0)
+	}
+	status.LastCommitAuthor = fields[1]
+	status.LastCommitSubject = fields[2]
+}
+
+// runGitTimeout runs a git command in dir within commandTimeout
+func runGitTimeout(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	return runGit(ctx, dir, args...)
+}
+
+// runGit runs a git command in dir and returns its stdout
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
 	cmd.Stdout = &out
 
 	if err := cmd.Run(); err != nil {
-		return nil, err
+		return "", err
 	}
+	return out.String(), nil
+}
+
+// parsePorcelainV2 parses `git status --porcelain=v2 --branch` output
+func parsePorcelainV2(out string) *Status {
+	status := &Status{}
 
-	lines := strings.Split(out.String(), "\n")
-	for _, line := range lines {
-		if len(line) < 3 {
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
 			continue
 		}
 
-		status.DirtyFiles++
+		switch line[0] {
+		case '#':
+			parseBranchHeader(status, line)
+		case '1', '2', 'u':
+			// "1 XY ..." ordinary change, "2 XY ..." rename or copy, "u XY ..." unmerged
+			if len(line) >= 4 {
+				countChange(status, line[0], line[2:4])
+			}
+		case '?':
+			countChange(status, '?', "")
+		}
+	}
+
+	return status
+}
+
+// countChange tallies one status entry of the given porcelain v2 kind
+// ('1', '2', 'u' or '?') with its two-letter XY code
+func countChange(status *Status, kind byte, code string) {
+	status.DirtyFiles++
+
+	switch kind {
+	case 'u':
+		status.Conflicts++
+		return
+	case '?':
+		status.Untracked++
+		return
+	case '2':
+		status.Renamed++
+	}
+
+	if strings.Contains(code, "A") {
+		status.Added++
+	}
+	if strings.Contains(code, "M") {
+		status.Modified++
+	}
+	if strings.Contains(code, "D") {
+		status.Deleted++
+	}
+}
+
+// parseBranchHeader reads a "# branch.*" header line
+func parseBranchHeader(status *Status, line string) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return
+	}
 
-		code := line[:2]
-		if strings.Contains(code, "A") {
-			status.Added++
+	switch fields[1] {
+	case "branch.oid":
+		if oid := fields[2]; oid != "(initial)" {
+			status.Head = oid[:min(len(oid), shortSHALength)]
+			status.HeadOID = oid
 		}
-		if strings.Contains(code, "M") {
-			status.Modified++
+	case "branch.head":
+		status.Detached = fields[2] == "(detached)"
+	case "branch.ab":
+		if len(fields) == 4 {
+			status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+			status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
 		}
-		if strings.Contains(code, "D") {
-			status.Deleted++
+	}
+}
+
+// operationMarkers maps files in the git directory to the operation they signal,
+// checked in order
+var operationMarkers = []struct {
+	path      string
+	operation string
+}{
+	{"rebase-merge", "rebase"},
+	{"rebase-apply/applying", "am"},
+	{"rebase-apply", "rebase"},
+	{"MERGE_HEAD", "merge"},
+	{"CHERRY_PICK_HEAD", "cherry-pick"},
+	{"REVERT_HEAD", "revert"},
+	{"BISECT_LOG", "bisect"},
+}
+
+// detectOperation reports which multi-step operation is in progress in gitDir
+func detectOperation(gitDir string) string {
+	for _, marker := range operationMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, marker.path)); err == nil {
+			return marker.operation
 		}
 	}
+	return ""
+}
 
-	return status, nil
+// worktreeName returns the name of the linked worktree whose git directory is
+// gitDir (.git/worktrees/<name>), or "" for the main worktree
+func worktreeName(gitDir string) string {
+	if _, err := os.Stat(filepath.Join(gitDir, "commondir")); err != nil {
+		return ""
+	}
+	return filepath.Base(gitDir)
 }
@@ -1,13 +1,18 @@
 package git
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetBranch(t *testing.T) {
 	// This test requires a git repo
 	// For now, test that it doesn't panic
-	branch, err := GetBranch()
+	branch, err := GetBranch("")
 
 	// In a non-git directory, expect error
 	if err != nil && branch != "" {
@@ -17,7 +22,7 @@ func TestGetBranch(t *testing.T) {
 
 func TestGetStatus(t *testing.T) {
 	// Test in current repo (should have git)
-	status, err := GetStatus()
+	status, err := GetStatus("", Options{})
 
 	// If we're in a git repo, should not error
 	// In a non-git directory, expect error
@@ -25,3 +30,273 @@ func TestGetStatus(t *testing.T) {
 		t.Error("expected nil status on error")
 	}
 }
+
+// initRepo creates a repository with one commit on branch in a temp directory
+func initRepo(t *testing.T, branch string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", branch)
+	writeFile(t, dir, "README.md", "hello\n")
+	gitCmd(t, dir, "add", "README.md")
+	gitCmd(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+// gitCommand builds a git command in dir with a fixed identity and no user config
+func gitCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	return cmd
+}
+
+// gitCmd runs a git command in dir, failing the test on error
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := gitCommand(dir, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetBranchInDir(t *testing.T) {
+	dir := initRepo(t, "feature-x")
+
+	branch, err := GetBranch(dir)
+	if err != nil {
+		t.Fatalf("GetBranch failed: %v", err)
+	}
+	if branch != "feature-x" {
+		t.Errorf("expected branch 'feature-x', got %q", branch)
+	}
+}
+
+func TestGetBranchNestedRepo(t *testing.T) {
+	outer := initRepo(t, "outer-branch")
+	inner := filepath.Join(outer, "vendor", "lib")
+	if err := os.MkdirAll(inner, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, inner, "init", "-q", "-b", "inner-branch")
+	writeFile(t, inner, "lib.go", "package lib\n")
+	gitCmd(t, inner, "add", "lib.go")
+	gitCmd(t, inner, "commit", "-q", "-m", "lib")
+
+	if branch, _ := GetBranch(inner); branch != "inner-branch" {
+		t.Errorf("expected nested repo branch 'inner-branch', got %q", branch)
+	}
+	if branch, _ := GetBranch(filepath.Join(outer, "vendor")); branch != "outer-branch" {
+		t.Errorf("expected outer repo branch from a subdirectory, got %q", branch)
+	}
+}
+
+func TestGetStatusInDir(t *testing.T) {
+	dir := initRepo(t, "main")
+
+	status, err := GetStatus(dir, Options{})
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.DirtyFiles != 0 {
+		t.Errorf("expected clean repo, got %+v", status)
+	}
+
+	writeFile(t, dir, "README.md", "changed\n")
+	writeFile(t, dir, "new.txt", "new\n")
+	gitCmd(t, dir, "add", "new.txt")
+
+	status, err = GetStatus(dir, Options{})
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.DirtyFiles != 2 || status.Modified != 1 || status.Added != 1 {
+		t.Errorf("expected 1 modified and 1 added file, got %+v", status)
+	}
+}
+
+func TestGetStatusNotARepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	if _, err := GetStatus(t.TempDir(), Options{}); err == nil {
+		t.Error("expected error outside a repository")
+	}
+}
+
+func TestParsePorcelainV2(t *testing.T) {
+	out := `# branch.oid 4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39
+# branch.head main
+# branch.upstream origin/main
+# branch.ab +2 -3
+1 .M N... 100644 100644 100644 abc abc README.md
+1 A. N... 000000 100644 100644 000 def new.go
+1 D. N... 100644 000000 000000 abc 000 old.go
+2 R. N... 100644 100644 100644 abc abc R100 renamed.go	original.go
+u UU N... 100644 100644 100644 100644 a b c conflict.go
+? scratch.txt
+? notes.md
+! ignored.log
+`
+
+	status := parsePorcelainV2(out)
+
+	want := Status{
+		DirtyFiles: 7,
+		Ahead:      2,
+		Behind:     3,
+		Added:      1,
+		Modified:   1,
+		Deleted:    1,
+		Renamed:    1,
+		Untracked:  2,
+		Conflicts:  1,
+		Head:       "4f2a9c1",
+		HeadOID:    "4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39",
+	}
+	if *status != want {
+		t.Errorf("parsePorcelainV2() = %+v, want %+v", *status, want)
+	}
+}
+
+func TestParsePorcelainV2Detached(t *testing.T) {
+	out := "# branch.oid 4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39\n# branch.head (detached)\n"
+
+	status := parsePorcelainV2(out)
+	if !status.Detached || status.Head != "4f2a9c1" {
+		t.Errorf("expected detached at 4f2a9c1, got %+v", *status)
+	}
+
+	status = parsePorcelainV2("# branch.oid (initial)\n# branch.head main\n")
+	if status.Detached || status.Head != "" {
+		t.Errorf("expected unborn branch without SHA, got %+v", *status)
+	}
+}
+
+func TestGetStatusDetachedTagAndStash(t *testing.T) {
+	dir := initRepo(t, "main")
+	gitCmd(t, dir, "tag", "v1.2.0")
+	writeFile(t, dir, "README.md", "second\n")
+	gitCmd(t, dir, "commit", "-q", "-am", "second")
+
+	writeFile(t, dir, "README.md", "stashed\n")
+	gitCmd(t, dir, "stash", "-q")
+	writeFile(t, dir, "README.md", "stashed again\n")
+	gitCmd(t, dir, "stash", "-q")
+
+	gitCmd(t, dir, "checkout", "-q", "--detach", "HEAD~1")
+	writeFile(t, dir, "untracked.txt", "?\n")
+
+	status, err := GetStatus(dir, Options{Stash: true, Tag: true})
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+
+	head := strings.TrimSpace(gitCmd(t, dir, "rev-parse", "--short=7", "HEAD"))
+	if !status.Detached || status.Head != head {
+		t.Errorf("expected detached at %s, got %+v", head, status)
+	}
+	if status.Tag != "v1.2.0" {
+		t.Errorf("expected nearest tag v1.2.0, got %q", status.Tag)
+	}
+	if status.Stashes != 2 {
+		t.Errorf("expected 2 stashes, got %d", status.Stashes)
+	}
+	if status.Untracked != 1 {
+		t.Errorf("expected 1 untracked file, got %d", status.Untracked)
+	}
+
+	// Turned off, the stash and tag commands don't run
+	status, err = GetStatus(dir, Options{})
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Tag != "" || status.Stashes != 0 {
+		t.Errorf("expected no tag or stash count when not requested, got %q %d", status.Tag, status.Stashes)
+	}
+}
+
+func TestGetStatusMergeConflict(t *testing.T) {
+	dir := initRepo(t, "main")
+	gitCmd(t, dir, "checkout", "-q", "-b", "topic")
+	writeFile(t, dir, "README.md", "topic\n")
+	gitCmd(t, dir, "commit", "-q", "-am", "topic")
+	gitCmd(t, dir, "checkout", "-q", "main")
+	writeFile(t, dir, "README.md", "main\n")
+	gitCmd(t, dir, "commit", "-q", "-am", "main")
+
+	// The merge fails with a conflict, which is what we want
+	_ = gitCommand(dir, "merge", "-q", "topic").Run()
+
+	status, err := GetStatus(dir, Options{})
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Operation != "merge" {
+		t.Errorf("expected merge in progress, got %q", status.Operation)
+	}
+	if status.Conflicts != 1 {
+		t.Errorf("expected 1 conflict, got %d", status.Conflicts)
+	}
+}
+
+func TestDetectOperation(t *testing.T) {
+	tests := map[string]string{
+		"rebase-merge":          "rebase",
+		"rebase-apply/applying": "am",
+		"CHERRY_PICK_HEAD":      "cherry-pick",
+		"REVERT_HEAD":           "revert",
+		"BISECT_LOG":            "bisect",
+	}
+
+	for marker, want := range tests {
+		gitDir := t.TempDir()
+		path := filepath.Join(gitDir, marker)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := detectOperation(gitDir); got != want {
+			t.Errorf("detectOperation with %s = %q, want %q", marker, got, want)
+		}
+	}
+
+	if got := detectOperation(t.TempDir()); got != "" {
+		t.Errorf("expected no operation, got %q", got)
+	}
+}
+
+func TestParseLastCommit(t *testing.T) {
+	status := &Status{}
+	parseLastCommit(status, "1700000000\x00Ada Lovelace\x00Fix the thing: with colons\x00 and NULs")
+	if !status.LastCommitTime.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected time %v", status.LastCommitTime)
+	}
+	if status.LastCommitAuthor != "Ada Lovelace" || status.LastCommitSubject != "Fix the thing: with colons\x00 and NULs" {
+		t.Errorf("unexpected author/subject %q %q", status.LastCommitAuthor, status.LastCommitSubject)
+	}
+
+	empty := &Status{}
+	parseLastCommit(empty, "garbage")
+	if !empty.LastCommitTime.IsZero() || empty.LastCommitAuthor != "" {
+		t.Errorf("expected nothing parsed from malformed output, got %+v", empty)
+	}
+}
@@ -0,0 +1,163 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// File modes as stored in trees and the index
+const (
+	modeTree     = 0o040000
+	modeSymlink  = 0o120000
+	modeGitlink  = 0o160000
+	modeTypeMask = 0o170000
+)
+
+// Index entry flags
+const (
+	flagExtended     = 0x4000
+	flagStageShift   = 12
+	flagSkipWorktree = 0x4000 // In the extended flags
+	flagIntentToAdd  = 0x2000 // In the extended flags
+)
+
+// indexEntry is one path in the index
+type indexEntry struct {
+	path         string
+	oid          string
+	mode         uint32
+	size         uint32
+	mtimeSec     uint32
+	mtimeNsec    uint32
+	stage        int
+	skipWorktree bool
+	intentToAdd  bool
+}
+
+// index is a parsed .git/index
+type index struct {
+	entries []indexEntry
+	mtime   int64 // Index file modification time in nanoseconds, for racy-clean checks
+}
+
+// errUnsupportedIndex marks index files whose entries live partly elsewhere
+var errUnsupportedIndex = errors.New("index: split and sparse indexes are not supported")
+
+// readIndex parses an index file (versions 2, 3 and 4). Split ("link") and
+// sparse ("sdir") indexes return errUnsupportedIndex: their entries alone
+// don't describe the whole tree.
+func readIndex(path string) (*index, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &index{}, nil // Fresh repository without an index
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, errors.New("index: bad signature")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("index: unsupported version %d", version)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+
+	idx := &index{entries: make([]indexEntry, 0, count), mtime: info.ModTime().UnixNano()}
+	pos := 12
+	previous := ""
+
+	for range count {
+		// 10 uint32 stat fields, 20-byte id, 16-bit flags
+		if len(data) < pos+62 {
+			return nil, errors.New("index: truncated entry")
+		}
+		start := pos
+		entry := indexEntry{
+			mtimeSec:  binary.BigEndian.Uint32(data[pos+8:]),
+			mtimeNsec: binary.BigEndian.Uint32(data[pos+12:]),
+			mode:      binary.BigEndian.Uint32(data[pos+24:]),
+			size:      binary.BigEndian.Uint32(data[pos+36:]),
+			oid:       hex.EncodeToString(data[pos+40 : pos+60]),
+		}
+		flags := binary.BigEndian.Uint16(data[pos+60:])
+		entry.stage = int(flags>>flagStageShift) & 3
+		pos += 62
+
+		if version >= 3 && flags&flagExtended != 0 {
+			if len(data) < pos+2 {
+				return nil, errors.New("index: truncated extended flags")
+			}
+			extended := binary.BigEndian.Uint16(data[pos:])
+			entry.skipWorktree = extended&flagSkipWorktree != 0
+			entry.intentToAdd = extended&flagIntentToAdd != 0
+			pos += 2
+		}
+
+		if version == 4 {
+			// Path is the previous path minus N trailing bytes plus a NUL-terminated suffix
+			strip, n := varintOffset(data[pos:])
+			if n == 0 || strip > len(previous) {
+				return nil, errors.New("index: malformed path compression")
+			}
+			pos += n
+			nul := bytes.IndexByte(data[pos:], 0)
+			if nul < 0 {
+				return nil, errors.New("index: unterminated path")
+			}
+			entry.path = previous[:len(previous)-strip] + string(data[pos:pos+nul])
+			pos += nul + 1
+		} else {
+			nul := bytes.IndexByte(data[pos:], 0)
+			if nul < 0 {
+				return nil, errors.New("index: unterminated path")
+			}
+			entry.path = string(data[pos : pos+nul])
+			// Entries are NUL-padded to a multiple of 8 bytes
+			pos += (nul+(pos-start)+8)&^7 - (pos - start)
+		}
+
+		previous = entry.path
+		idx.entries = append(idx.entries, entry)
+	}
+
+	// Extensions follow the entries, before the trailing checksum
+	for pos+8 <= len(data)-sha1.Size {
+		switch string(data[pos : pos+4]) {
+		case "link", "sdir":
+			return nil, errUnsupportedIndex
+		}
+		pos += 8 + int(binary.BigEndian.Uint32(data[pos+4:pos+8]))
+	}
+
+	return idx, nil
+}
+
+// varintOffset decodes git's offset varint (each continuation adds one)
+func varintOffset(b []byte) (int, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	c := b[0]
+	value := int(c & 0x7f)
+	i := 1
+	for c&0x80 != 0 {
+		if i >= len(b) || i > 8 {
+			return 0, 0
+		}
+		c = b[i]
+		value = (value+1)<<7 | int(c&0x7f)
+		i++
+	}
+	return value, i
+}
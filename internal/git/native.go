@@ -0,0 +1,949 @@
+package git
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// maxWalk bounds history walks (ahead/behind, nearest tag) on huge repositories
+const maxWalk = 50000
+
+// walkTimeout bounds each walk like the matching git command (tests shorten it)
+var walkTimeout = commandTimeout
+
+// walkContext bounds one walk, so a huge repository can't outlive the refresh
+func walkContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), walkTimeout)
+}
+
+// NativeProvider reads HEAD, refs, packed-refs, objects and the index
+// directly instead of running git
+type NativeProvider struct {
+	Options
+}
+
+// Branch returns the current branch, or "HEAD" when detached (like git rev-parse)
+func (NativeProvider) Branch(dir string) (string, error) {
+	repo, err := openRepo(dir)
+	if err != nil {
+		return "", err
+	}
+
+	ref, _, err := repo.head()
+	if err != nil {
+		return "", err
+	}
+	if ref == "" {
+		return "HEAD", nil
+	}
+	return strings.TrimPrefix(ref, "refs/heads/"), nil
+}
+
+// Status computes the same information as git status --porcelain=v2 --branch
+func (p NativeProvider) Status(dir string) (*Status, error) {
+	repo, err := openRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, head, err := repo.head()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := openObjectStore(filepath.Join(repo.commonDir, "objects"))
+	if err != nil {
+		return nil, err
+	}
+	defer store.close()
+
+	idx, err := readIndex(filepath.Join(repo.gitDir, "index"))
+	if errors.Is(err, errUnsupportedIndex) {
+		return ExecProvider{p.Options}.Status(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Detached: ref == "", HeadOID: head}
+	headTree := map[string]treeEntry{}
+	var commit *commitInfo
+	if head != "" {
+		status.Head = head[:min(len(head), shortSHALength)]
+		if commit, err = store.readCommit(head); err != nil {
+			return nil, err
+		}
+		if err := store.flattenTree(commit.tree, "", headTree); err != nil {
+			return nil, err
+		}
+	}
+
+	// Comparing the worktree is bounded like git status; a walk cut short
+	// leaves its counts out but keeps the rest of the status
+	ctx, cancel := walkContext()
+	defer cancel()
+	changes, err := repo.changes(ctx, idx, headTree)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	for _, change := range changes {
+		countChange(status, change.kind, change.xy)
+		if p.LineStats {
+			repo.tallyLines(store, change, status)
+		}
+	}
+
+	if untracked, err := repo.untracked(ctx, idx); err == nil {
+		status.DirtyFiles += untracked
+		status.Untracked += untracked
+	} else if !errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	if commit != nil && p.lastCommit(status.DirtyFiles) {
+		status.LastCommitTime = time.Unix(commit.time, 0)
+		status.LastCommitAuthor = commit.author
+		status.LastCommitSubject = commit.subject
+	}
+
+	if ref != "" && head != "" {
+		if upstream := repo.upstream(strings.TrimPrefix(ref, "refs/heads/")); upstream != "" {
+			if target, err := repo.resolveRef(upstream); err == nil {
+				ctx, cancel := walkContext()
+				// A walk cut short leaves the counts out rather than wrong
+				if ahead, behind, err := aheadBehind(ctx, store, head, target); err == nil {
+					status.Ahead, status.Behind = ahead, behind
+				}
+				cancel()
+			}
+		}
+	}
+
+	if p.Stash {
+		status.Stashes = countLines(filepath.Join(repo.commonDir, "logs", "refs", "stash"))
+	}
+	status.Operation = detectOperation(repo.gitDir)
+	status.Worktree = worktreeName(repo.gitDir)
+	if p.Tag && head != "" {
+		ctx, cancel := walkContext()
+		status.Tag = nearestTag(ctx, store, repo.tags(store), head)
+		cancel()
+	}
+
+	if p.CompareBase && head != "" {
+		ctx, cancel := walkContext()
+		// A missing base branch or an expired walk just leaves the comparison out
+		_ = repo.compareBase(ctx, store, head, p.BaseBranch, status)
+		cancel()
+	}
+
+	return status, nil
+}
+
+// Commits walks history from HEAD like git log, leaving out everything
+// reachable from since
+func (NativeProvider) Commits(dir, since string) ([]Commit, error) {
+	repo, err := openRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	_, head, err := repo.head()
+	if err != nil {
+		return nil, err
+	}
+	if head == "" {
+		return nil, errors.New("HEAD has no commits yet")
+	}
+
+	store, err := openObjectStore(filepath.Join(repo.commonDir, "objects"))
+	if err != nil {
+		return nil, err
+	}
+	defer store.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), commitsTimeout)
+	defer cancel()
+	oids, err := exclusiveCommits(ctx, store, head, since, maxCommits)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, oid := range oids {
+		info, err := store.readCommit(oid)
+		if err != nil {
+			return nil, err
+		}
+		commit := Commit{ID: oid, Subject: info.subject, Time: time.Unix(info.time, 0)}
+
+		// Merges have no diff in git log's numstat
+		if len(info.parents) <= 1 {
+			trees := [2]map[string]treeEntry{{}, {}}
+			if len(info.parents) == 1 {
+				parent, err := store.readCommit(info.parents[0])
+				if err != nil {
+					return nil, err
+				}
+				if err := store.flattenTree(parent.tree, "", trees[0]); err != nil {
+					return nil, err
+				}
+			}
+			if err := store.flattenTree(info.tree, "", trees[1]); err != nil {
+				return nil, err
+			}
+			commit.Lines = diffTrees(store, trees[0], trees[1])
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// repo locates the directories of a repository or linked worktree
+type repo struct {
+	workTree  string
+	gitDir    string // Per-worktree: HEAD, index, operation state
+	commonDir string // Shared: objects, refs, config
+}
+
+// openRepo finds the repository containing dir
+func openRepo(dir string) (*repo, error) {
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			gitDir := dotGit
+			if !info.IsDir() {
+				// Linked worktrees and submodules use a "gitdir: <path>" file
+				if gitDir, err = readGitFile(dotGit); err != nil {
+					return nil, err
+				}
+			}
+			return &repo{workTree: dir, gitDir: gitDir, commonDir: commonDir(gitDir)}, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, errors.New("not a git repository")
+		}
+		dir = parent
+	}
+}
+
+// readGitFile follows a .git file to the real git directory
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%s: not a gitdir file", path)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return filepath.Clean(target), nil
+}
+
+// commonDir returns the shared git directory of a (possibly linked) worktree
+func commonDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	common := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+	return filepath.Clean(common)
+}
+
+// head returns the ref HEAD points to (empty when detached) and the commit
+// it resolves to (empty on an unborn branch)
+func (r *repo) head() (string, string, error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return "", "", err
+	}
+	content := strings.TrimSpace(string(data))
+
+	ref, symbolic := strings.CutPrefix(content, "ref: ")
+	if !symbolic {
+		return "", content, nil
+	}
+
+	oid, err := r.resolveRef(ref)
+	if err != nil {
+		return ref, "", nil // Unborn branch
+	}
+	return ref, oid, nil
+}
+
+// refDir returns where a ref is stored: per-worktree refs live in gitDir
+func (r *repo) refDir(name string) string {
+	for _, prefix := range []string{"refs/bisect/", "refs/worktree/", "refs/rewritten/"} {
+		if strings.HasPrefix(name, prefix) {
+			return r.gitDir
+		}
+	}
+	if !strings.HasPrefix(name, "refs/") {
+		return r.gitDir // HEAD, ORIG_HEAD, ...
+	}
+	return r.commonDir
+}
+
+// resolveRef follows a ref (loose or packed, possibly symbolic) to an object id
+func (r *repo) resolveRef(name string) (string, error) {
+	for range 5 {
+		data, err := os.ReadFile(filepath.Join(r.refDir(name), filepath.FromSlash(name)))
+		if err != nil {
+			refs, _ := r.packedRefs()
+			if oid, ok := refs[name]; ok {
+				return oid, nil
+			}
+			return "", fmt.Errorf("ref %s not found", name)
+		}
+
+		content := strings.TrimSpace(string(data))
+		target, symbolic := strings.CutPrefix(content, "ref: ")
+		if !symbolic {
+			return content, nil
+		}
+		name = target
+	}
+	return "", fmt.Errorf("ref %s: too many levels of symbolic refs", name)
+}
+
+// packedRefs parses packed-refs into ref ids and peeled tag targets
+func (r *repo) packedRefs() (map[string]string, map[string]string) {
+	refs := map[string]string{}
+	peeled := map[string]string{}
+
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		return refs, peeled
+	}
+	defer func() { _ = f.Close() }()
+
+	last := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "^"):
+			// Peeled target of the preceding annotated tag
+			if last != "" {
+				peeled[last] = line[1:]
+			}
+		default:
+			oid, name, ok := strings.Cut(line, " ")
+			if ok {
+				refs[name] = oid
+				last = name
+			}
+		}
+	}
+	return refs, peeled
+}
+
+// tags maps commit ids to the tags pointing at them
+func (r *repo) tags(store *objectStore) map[string][]string {
+	refs, peeled := r.packedRefs()
+	tagged := map[string]string{} // Tag name → commit
+	for name, oid := range refs {
+		if tag, ok := strings.CutPrefix(name, "refs/tags/"); ok {
+			if target, ok := peeled[name]; ok {
+				oid = target
+			}
+			tagged[tag] = oid
+		}
+	}
+
+	// Loose tags override packed ones
+	tagsDir := filepath.Join(r.commonDir, "refs", "tags")
+	_ = filepath.WalkDir(tagsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(tagsDir, path)
+		tagged[filepath.ToSlash(rel)] = strings.TrimSpace(string(data))
+		return nil
+	})
+
+	commits := map[string][]string{}
+	for tag, oid := range tagged {
+		if commit, err := store.peel(oid); err == nil {
+			commits[commit] = append(commits[commit], tag)
+		}
+	}
+	return commits
+}
+
+// upstream returns the remote-tracking ref configured for branch, if any
+func (r *repo) upstream(branch string) string {
+	config := readConfig(filepath.Join(r.commonDir, "config"))
+	section := `branch "` + branch + `"`
+	remote := config[section+".remote"]
+	merge := config[section+".merge"]
+	if remote == "" || merge == "" {
+		return ""
+	}
+	if remote == "." {
+		return merge
+	}
+	return "refs/remotes/" + remote + "/" + strings.TrimPrefix(merge, "refs/heads/")
+}
+
+// readConfig reads a git config file into `section "subsection".key` entries
+func readConfig(path string) map[string]string {
+	values := map[string]string{}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer func() { _ = f.Close() }()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[':
+			header := strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			name, sub, hasSub := strings.Cut(header, " ")
+			section = strings.ToLower(name)
+			if hasSub {
+				section += " " + strings.TrimSpace(sub)
+			}
+		default:
+			key, value, _ := strings.Cut(line, "=")
+			values[section+"."+strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return values
+}
+
+// change is one status entry in porcelain v2 terms
+type change struct {
+	kind  byte   // '1' ordinary, '2' rename, 'u' unmerged
+	xy    string // Staged and unstaged status letters, "." for unchanged
+	path  string
+	head  string // Blob id in HEAD (the source for renames), empty when added
+	index string // Blob id in the index (ours when unmerged), empty when deleted
+}
+
+// changes compares the index with the HEAD tree (staged) and the worktree
+// (unstaged). It gives up when ctx expires.
+func (r *repo) changes(ctx context.Context, idx *index, headTree map[string]treeEntry) ([]change, error) {
+	conflicted := map[string]bool{}
+	ours := map[string]string{}
+	staged := map[string]indexEntry{}
+	for _, entry := range idx.entries {
+		if entry.stage > 0 {
+			conflicted[entry.path] = true
+			if entry.stage == 2 {
+				ours[entry.path] = entry.oid
+			}
+		} else {
+			staged[entry.path] = entry
+		}
+	}
+
+	var changes []change
+	for path := range conflicted {
+		changes = append(changes, change{kind: 'u', xy: "UU", path: path, head: headTree[path].oid, index: ours[path]})
+	}
+
+	added := map[string][]int{} // Object id → positions of staged additions, for rename pairing
+	for _, entry := range idx.entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if entry.stage > 0 || conflicted[entry.path] {
+			continue
+		}
+
+		x := byte('.')
+		if head, ok := headTree[entry.path]; !ok {
+			if !entry.intentToAdd {
+				x = 'A'
+			}
+		} else if head.oid != entry.oid || head.mode != entry.mode {
+			x = 'M'
+		}
+
+		y := r.worktreeState(entry, idx.mtime)
+		if x == '.' && y == '.' {
+			continue
+		}
+		if x == 'A' {
+			added[entry.oid] = append(added[entry.oid], len(changes))
+		}
+		changes = append(changes, change{
+			kind:  '1',
+			xy:    string([]byte{x, y}),
+			path:  entry.path,
+			head:  headTree[entry.path].oid,
+			index: entry.oid,
+		})
+	}
+
+	for path, head := range headTree {
+		if _, ok := staged[path]; ok || conflicted[path] {
+			continue
+		}
+		// Exact renames: a deleted file re-added elsewhere with the same content
+		if positions := added[head.oid]; len(positions) > 0 {
+			added[head.oid] = positions[1:]
+			c := &changes[positions[0]]
+			c.kind = '2'
+			c.xy = "R" + c.xy[1:]
+			c.head = head.oid
+			continue
+		}
+		changes = append(changes, change{kind: '1', xy: "D.", path: path, head: head.oid})
+	}
+
+	return changes, nil
+}
+
+// worktreeState reports how the worktree file differs from its index entry:
+// '.' unchanged, 'M' modified, 'D' deleted, 'A' intent to add
+func (r *repo) worktreeState(entry indexEntry, indexMtime int64) byte {
+	if entry.skipWorktree || entry.mode&modeTypeMask == modeGitlink {
+		return '.'
+	}
+	if entry.intentToAdd {
+		return 'A'
+	}
+
+	path := filepath.Join(r.workTree, filepath.FromSlash(entry.path))
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 'D'
+	}
+
+	isLink := info.Mode()&os.ModeSymlink != 0
+	if (entry.mode&modeTypeMask == modeSymlink) != isLink || (!isLink && !info.Mode().IsRegular()) {
+		return 'M'
+	}
+	if !isLink && runtime.GOOS != "windows" && (entry.mode&0o111 != 0) != (info.Mode().Perm()&0o111 != 0) {
+		return 'M'
+	}
+	if uint32(info.Size()) != entry.size {
+		return 'M'
+	}
+
+	// Matching stat data means unchanged, unless the file may have been
+	// modified in the same instant the index was written ("racy git")
+	mtime := info.ModTime()
+	statClean := uint32(mtime.Unix()) == entry.mtimeSec &&
+		(entry.mtimeNsec == 0 || uint32(mtime.Nanosecond()) == entry.mtimeNsec)
+	if statClean && mtime.UnixNano() < indexMtime {
+		return '.'
+	}
+
+	oid, err := hashBlob(path, info)
+	if err != nil || oid != entry.oid {
+		return 'M'
+	}
+	return '.'
+}
+
+// hashBlob computes the blob id of a worktree file or symlink
+func hashBlob(path string, info os.FileInfo) (string, error) {
+	h := sha1.New()
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		_, _ = fmt.Fprintf(h, "blob %d\x00%s", len(target), target)
+		return fmt.Sprintf("%x", h.Sum(nil)), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, _ = fmt.Fprintf(h, "blob %d\x00", info.Size())
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// untracked counts untracked, non-ignored entries the way git status does:
+// a directory without tracked files counts once. It gives up when ctx expires.
+func (r *repo) untracked(ctx context.Context, idx *index) (int, error) {
+	tracked := map[string]bool{}
+	trackedDirs := map[string]bool{}
+	for _, entry := range idx.entries {
+		tracked[entry.path] = true
+		for dir := entry.path; ; {
+			slash := strings.LastIndexByte(dir, '/')
+			if slash < 0 {
+				break
+			}
+			dir = dir[:slash]
+			if trackedDirs[dir+"/"] {
+				break
+			}
+			trackedDirs[dir+"/"] = true
+		}
+	}
+
+	// Lowest precedence first: the last matching rule wins
+	rules := ignoreRules{}.loadIgnoreFile(r.excludesFile(), "")
+	rules = rules.loadIgnoreFile(filepath.Join(r.commonDir, "info", "exclude"), "")
+	count := r.walkUntracked(ctx, "", rules, tracked, trackedDirs)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// excludesFile returns the global ignore file: core.excludesFile from the
+// repository or global config, else git/ignore under $XDG_CONFIG_HOME
+func (r *repo) excludesFile() string {
+	home, _ := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && home != "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	var configs []string
+	if global := os.Getenv("GIT_CONFIG_GLOBAL"); global != "" {
+		configs = append(configs, global)
+	} else {
+		if configHome != "" {
+			configs = append(configs, filepath.Join(configHome, "git", "config"))
+		}
+		if home != "" {
+			configs = append(configs, filepath.Join(home, ".gitconfig"))
+		}
+	}
+	configs = append(configs, filepath.Join(r.commonDir, "config"))
+
+	path := ""
+	for _, config := range configs {
+		if value := readConfig(config)["core.excludesfile"]; value != "" {
+			path = value
+		}
+	}
+
+	switch {
+	case path == "" && configHome != "":
+		return filepath.Join(configHome, "git", "ignore")
+	case strings.HasPrefix(path, "~/") && home != "":
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
+
+// walkUntracked counts untracked entries in the directory rel ("" or "dir/")
+func (r *repo) walkUntracked(ctx context.Context, rel string, rules ignoreRules, tracked, trackedDirs map[string]bool) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+	dir := filepath.Join(r.workTree, filepath.FromSlash(rel))
+	rules = rules.loadIgnoreFile(filepath.Join(dir, ".gitignore"), rel)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		path := rel + entry.Name()
+
+		if entry.IsDir() {
+			switch {
+			case tracked[path]:
+				// Submodule
+			case trackedDirs[path+"/"]:
+				count += r.walkUntracked(ctx, path+"/", rules, tracked, trackedDirs)
+			case rules.ignored(path, true):
+			case r.hasUntrackedContent(ctx, path+"/", rules):
+				count++
+			}
+			continue
+		}
+
+		if !tracked[path] && !rules.ignored(path, false) {
+			count++
+		}
+	}
+	return count
+}
+
+// hasUntrackedContent reports whether an untracked directory contains any
+// non-ignored file (empty directories don't show up in git status)
+func (r *repo) hasUntrackedContent(ctx context.Context, rel string, rules ignoreRules) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	dir := filepath.Join(r.workTree, filepath.FromSlash(rel))
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true // Nested repository
+	}
+	rules = rules.loadIgnoreFile(filepath.Join(dir, ".gitignore"), rel)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		path := rel + entry.Name()
+		if entry.IsDir() {
+			if !rules.ignored(path, true) && r.hasUntrackedContent(ctx, path+"/", rules) {
+				return true
+			}
+		} else if !rules.ignored(path, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// commitQueue orders commits newest first, then by insertion (breadth-first on ties)
+type commitQueue []queuedCommit
+
+type queuedCommit struct {
+	oid  string
+	time int64
+	seq  int
+}
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	if q[i].time != q[j].time {
+		return q[i].time > q[j].time
+	}
+	return q[i].seq < q[j].seq
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(queuedCommit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// historyWalk visits commits newest first until maxWalk steps or its context ends
+type historyWalk struct {
+	ctx    context.Context
+	store  *objectStore
+	queue  commitQueue
+	queued map[string]bool
+	seq    int
+}
+
+func newHistoryWalk(ctx context.Context, store *objectStore) *historyWalk {
+	return &historyWalk{ctx: ctx, store: store, queued: map[string]bool{}}
+}
+
+// more reports whether the walk may take another step
+func (w *historyWalk) more(steps int) bool {
+	return w.queue.Len() > 0 && steps < maxWalk && w.ctx.Err() == nil
+}
+
+// push queues a commit once
+func (w *historyWalk) push(oid string) {
+	if w.queued[oid] {
+		return
+	}
+	commit, err := w.store.readCommit(oid)
+	if err != nil {
+		return // Missing objects (shallow clones) end the walk on that side
+	}
+	w.queued[oid] = true
+	heap.Push(&w.queue, queuedCommit{oid: oid, time: commit.time, seq: w.seq})
+	w.seq++
+}
+
+// requeue visits an already popped commit again, e.g. after it was reached
+// from another side (commits made in the same second can come out of order)
+func (w *historyWalk) requeue(oid string) {
+	delete(w.queued, oid)
+	w.push(oid)
+}
+
+// pop returns the newest queued commit and its parents
+func (w *historyWalk) pop() (string, []string) {
+	item := heap.Pop(&w.queue).(queuedCommit)
+	commit, _ := w.store.readCommit(item.oid)
+	return item.oid, commit.parents
+}
+
+// aheadBehind counts commits reachable only from local and only from upstream.
+// The counts are unreliable when ctx expired, so its error is returned.
+func aheadBehind(ctx context.Context, store *objectStore, local, upstream string) (int, int, error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+
+	const fromLocal, fromUpstream = 1, 2
+	flags := map[string]int{local: fromLocal, upstream: fromUpstream}
+	walk := newHistoryWalk(ctx, store)
+	walk.push(local)
+	walk.push(upstream)
+
+	ahead, behind := 0, 0
+	for steps := 0; walk.more(steps); steps++ {
+		// Stop once only shared history remains
+		pending := false
+		for _, item := range walk.queue {
+			if flags[item.oid] != fromLocal|fromUpstream {
+				pending = true
+				break
+			}
+		}
+		if !pending {
+			break
+		}
+
+		oid, parents := walk.pop()
+		switch flags[oid] {
+		case fromLocal:
+			ahead++
+		case fromUpstream:
+			behind++
+		}
+		for _, parent := range parents {
+			flags[parent] |= flags[oid]
+			walk.push(parent)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+// exclusiveCommits lists up to limit commits reachable from head but not
+// from since (which may be empty), newest first
+func exclusiveCommits(ctx context.Context, store *objectStore, head, since string, limit int) ([]string, error) {
+	const fromHead, fromSince = 1, 2
+	flags := map[string]int{head: fromHead}
+	walk := newHistoryWalk(ctx, store)
+	walk.push(head)
+	if since != "" {
+		flags[since] |= fromSince
+		walk.push(since)
+	}
+
+	var order []string
+	for steps := 0; walk.more(steps) && len(order) < limit; steps++ {
+		// Stop once everything left is reachable from since
+		pending := false
+		for _, item := range walk.queue {
+			if flags[item.oid]&fromSince == 0 {
+				pending = true
+				break
+			}
+		}
+		if !pending {
+			break
+		}
+
+		oid, parents := walk.pop()
+		if flags[oid] == fromHead {
+			order = append(order, oid)
+		}
+		for _, parent := range parents {
+			flags[parent] |= flags[oid]
+			walk.push(parent)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// A commit can turn out to be reachable from since after it was visited
+	commits := order[:0]
+	for _, oid := range order {
+		if flags[oid] == fromHead {
+			commits = append(commits, oid)
+		}
+	}
+	return commits, nil
+}
+
+// nearestTag returns the first tagged commit found walking back from head
+func nearestTag(ctx context.Context, store *objectStore, tags map[string][]string, head string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	walk := newHistoryWalk(ctx, store)
+	walk.push(head)
+	for steps := 0; walk.more(steps); steps++ {
+		oid, parents := walk.pop()
+		if names := tags[oid]; len(names) > 0 {
+			best := names[0]
+			for _, name := range names[1:] {
+				best = max(best, name)
+			}
+			return best
+		}
+		for _, parent := range parents {
+			walk.push(parent)
+		}
+	}
+	return ""
+}
+
+// countLines counts lines in a file, 0 if it doesn't exist
+func countLines(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = f.Close() }()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		count++
+	}
+	return count
+}
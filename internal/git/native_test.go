@@ -0,0 +1,565 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain pins the user's git config to an empty home, so git and the
+// native backend see the same global excludes wherever the tests run
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "git-home")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	_ = os.Setenv("HOME", home)
+	_ = os.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	_ = os.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	_ = os.Unsetenv("GIT_CONFIG_GLOBAL")
+
+	code := m.Run()
+	_ = os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// allOptions asks for every piece of optional information
+var allOptions = Options{CompareBase: true, Stash: true, Tag: true, LineStats: true, LastCommit: true}
+
+// assertParity checks the native backend reports exactly what git itself does,
+// comparing against main/master
+func assertParity(t *testing.T, dir string) {
+	t.Helper()
+	assertParityWith(t, dir, allOptions)
+}
+
+func assertParityWith(t *testing.T, dir string, opts Options) {
+	t.Helper()
+
+	wantBranch, wantErr := ExecProvider{opts}.Branch(dir)
+	gotBranch, err := NativeProvider{opts}.Branch(dir)
+	if wantErr == nil && (err != nil || gotBranch != wantBranch) {
+		t.Errorf("Branch: native %q (%v), exec %q", gotBranch, err, wantBranch)
+	}
+
+	want, err := ExecProvider{opts}.Status(dir)
+	if err != nil {
+		t.Fatalf("exec status failed: %v", err)
+	}
+	got, err := NativeProvider{opts}.Status(dir)
+	if err != nil {
+		t.Fatalf("native status failed: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("Status mismatch\nnative: %+v\nexec:   %+v", *got, *want)
+	}
+}
+
+// workingTreeRepo builds a repository with every kind of status entry
+func workingTreeRepo(t *testing.T) string {
+	t.Helper()
+	dir := initRepo(t, "main")
+
+	writeFile(t, dir, ".gitignore", "*.log\nbuild/\n!keep.log\n")
+	if err := os.MkdirAll(filepath.Join(dir, "src", "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "src/app.go", "package app\n")
+	writeFile(t, dir, "src/util.go", "package app\n\nfunc util() {}\n")
+	writeFile(t, dir, "src/nested/.gitignore", "/local.txt\n")
+	writeFile(t, dir, "src/nested/deep.go", "package nested\n")
+	writeFile(t, dir, "moved.txt", "same content\n")
+	writeFile(t, dir, "gone.txt", "bye\n")
+	writeFile(t, dir, "staged-gone.txt", "bye\n")
+	writeFile(t, dir, "script.sh", "#!/bin/sh\n")
+	gitCmd(t, dir, "add", "-A")
+	gitCmd(t, dir, "commit", "-q", "-m", "tree")
+
+	// Unstaged modification and staged modification
+	writeFile(t, dir, "README.md", "changed in worktree\n")
+	writeFile(t, dir, "src/app.go", "package app\n\n// staged\n")
+	gitCmd(t, dir, "add", "src/app.go")
+	// Staged then modified again
+	writeFile(t, dir, "src/util.go", "package app\n\nfunc util() { /* staged */ }\n")
+	gitCmd(t, dir, "add", "src/util.go")
+	writeFile(t, dir, "src/util.go", "package app\n\nfunc util() { /* again */ }\n")
+	// Deletions, unstaged and staged
+	if err := os.Remove(filepath.Join(dir, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, dir, "rm", "-q", "staged-gone.txt")
+	// Staged addition and exact rename
+	writeFile(t, dir, "added.go", "package main\n")
+	gitCmd(t, dir, "add", "added.go")
+	gitCmd(t, dir, "mv", "moved.txt", "renamed.txt")
+	// Intent to add
+	writeFile(t, dir, "later.txt", "later\n")
+	gitCmd(t, dir, "add", "-N", "later.txt")
+	// Untracked: a file, a directory counted once, ignored files and dirs
+	writeFile(t, dir, "notes.md", "notes\n")
+	if err := os.MkdirAll(filepath.Join(dir, "scratch", "more"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "scratch/a.txt", "a\n")
+	writeFile(t, dir, "scratch/more/b.txt", "b\n")
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "debug.log", "ignored\n")
+	writeFile(t, dir, "keep.log", "negated\n")
+	if err := os.MkdirAll(filepath.Join(dir, "build"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "build/out.bin", "ignored\n")
+	writeFile(t, dir, "src/nested/local.txt", "ignored by nested .gitignore\n")
+	writeFile(t, dir, "src/nested/other.txt", "untracked\n")
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(filepath.Join(dir, "script.sh"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestNativeParityWorkingTree(t *testing.T) {
+	dir := workingTreeRepo(t)
+	assertParity(t, dir)
+
+	// Same answers from a subdirectory
+	assertParity(t, filepath.Join(dir, "src"))
+}
+
+func TestNativeParityPackedRepository(t *testing.T) {
+	dir := workingTreeRepo(t)
+	gitCmd(t, dir, "tag", "-a", "v1.0.0", "-m", "release")
+	gitCmd(t, dir, "rm", "-q", "--cached", "later.txt") // Stash refuses intent-to-add entries
+	gitCmd(t, dir, "stash", "-q")
+	gitCmd(t, dir, "gc", "-q")
+
+	// Objects and refs now live in packfiles and packed-refs
+	if loose, _ := filepath.Glob(filepath.Join(dir, ".git", "refs", "tags", "*")); len(loose) != 0 {
+		t.Fatalf("expected refs to be packed, found %v", loose)
+	}
+	assertParity(t, dir)
+}
+
+func TestNativeParityIndexV4(t *testing.T) {
+	dir := workingTreeRepo(t)
+	gitCmd(t, dir, "update-index", "--index-version", "4")
+	assertParity(t, dir)
+}
+
+func TestNativeParityDetachedAndTags(t *testing.T) {
+	dir := initRepo(t, "main")
+	gitCmd(t, dir, "tag", "v0.1.0")
+	for _, msg := range []string{"two", "three"} {
+		writeFile(t, dir, "README.md", msg+"\n")
+		gitCmd(t, dir, "commit", "-q", "-am", msg)
+	}
+	gitCmd(t, dir, "tag", "-a", "v0.2.0", "-m", "annotated")
+	writeFile(t, dir, "README.md", "four\n")
+	gitCmd(t, dir, "commit", "-q", "-am", "four")
+
+	assertParity(t, dir)
+
+	gitCmd(t, dir, "checkout", "-q", "--detach", "HEAD~2")
+	assertParity(t, dir)
+}
+
+func TestNativeParityAheadBehind(t *testing.T) {
+	remote := initRepo(t, "main")
+	dir := filepath.Join(t.TempDir(), "clone")
+	gitCmd(t, remote, "clone", "-q", remote, dir)
+
+	// Upstream gains one commit, local gains two
+	writeFile(t, remote, "upstream.txt", "upstream\n")
+	gitCmd(t, remote, "add", "upstream.txt")
+	gitCmd(t, remote, "commit", "-q", "-m", "upstream")
+	gitCmd(t, dir, "fetch", "-q")
+	for _, name := range []string{"one.txt", "two.txt"} {
+		writeFile(t, dir, name, name+"\n")
+		gitCmd(t, dir, "add", name)
+		gitCmd(t, dir, "commit", "-q", "-m", name)
+	}
+
+	assertParity(t, dir)
+
+	got, _ := NativeProvider{}.Status(dir)
+	if got.Ahead != 2 || got.Behind != 1 {
+		t.Errorf("expected ahead 2 behind 1, got ahead %d behind %d", got.Ahead, got.Behind)
+	}
+
+	gitCmd(t, dir, "pack-refs", "--all")
+	assertParity(t, dir)
+}
+
+func TestNativeParityConflict(t *testing.T) {
+	dir := initRepo(t, "main")
+	gitCmd(t, dir, "checkout", "-q", "-b", "topic")
+	writeFile(t, dir, "README.md", "topic\n")
+	gitCmd(t, dir, "commit", "-q", "-am", "topic")
+	gitCmd(t, dir, "checkout", "-q", "main")
+	writeFile(t, dir, "README.md", "main\n")
+	gitCmd(t, dir, "commit", "-q", "-am", "main")
+	_ = gitCommand(dir, "merge", "-q", "topic").Run()
+
+	assertParity(t, dir)
+}
+
+func TestNativeParityLinkedWorktree(t *testing.T) {
+	dir := initRepo(t, "main")
+	linked := filepath.Join(t.TempDir(), "linked")
+	gitCmd(t, dir, "worktree", "add", "-q", "-b", "feature", linked)
+	writeFile(t, linked, "README.md", "feature work\n")
+	writeFile(t, linked, "new.txt", "new\n")
+
+	assertParity(t, linked)
+
+	branch, err := NativeProvider{}.Branch(linked)
+	if err != nil || branch != "feature" {
+		t.Errorf("expected linked worktree branch 'feature', got %q (%v)", branch, err)
+	}
+	if status, _ := (NativeProvider{}).Status(linked); status.Worktree != "linked" {
+		t.Errorf("expected worktree name 'linked', got %q", status.Worktree)
+	}
+	if status, _ := (NativeProvider{}).Status(dir); status.Worktree != "" {
+		t.Errorf("expected no worktree name in the main worktree, got %q", status.Worktree)
+	}
+}
+
+func TestNativeParityBase(t *testing.T) {
+	dir := workingTreeRepo(t)
+	gitCmd(t, dir, "add", "-A")
+	gitCmd(t, dir, "commit", "-q", "-m", "work")
+	gitCmd(t, dir, "checkout", "-q", "-b", "feature")
+
+	writeFile(t, dir, "README.md", "rewritten\nwith more\nlines\n")
+	gitCmd(t, dir, "mv", "renamed.txt", "renamed-again.txt")
+	gitCmd(t, dir, "rm", "-q", "notes.md")
+	writeFile(t, dir, "data.bin", "binary\x00content\n")
+	gitCmd(t, dir, "add", "-A")
+	gitCmd(t, dir, "commit", "-q", "-m", "feature one")
+	writeFile(t, dir, "feature.go", "package main\n\nfunc feature() {}\n")
+	gitCmd(t, dir, "add", "feature.go")
+	gitCmd(t, dir, "commit", "-q", "-m", "feature two")
+
+	// main moves on too; only the feature side counts
+	gitCmd(t, dir, "checkout", "-q", "main")
+	writeFile(t, dir, "main-only.txt", "main\n")
+	gitCmd(t, dir, "add", "main-only.txt")
+	gitCmd(t, dir, "commit", "-q", "-m", "main moves")
+	gitCmd(t, dir, "checkout", "-q", "feature")
+
+	assertParity(t, dir)
+
+	got, err := NativeProvider{Options{CompareBase: true}}.Status(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Base != "main" || got.BaseCommits != 2 {
+		t.Errorf("expected 2 commits since main, got %q %d", got.Base, got.BaseCommits)
+	}
+	if got.BaseLines.IsZero() {
+		t.Error("expected line changes since main")
+	}
+
+	// An explicit base, a tag and a missing branch
+	gitCmd(t, dir, "tag", "-a", "base-tag", "-m", "tag", "main~1")
+	for _, base := range []string{"master", "main", "base-tag", "refs/heads/main"} {
+		opts := allOptions
+		opts.BaseBranch = base
+		assertParityWith(t, dir, opts)
+	}
+
+	gitCmd(t, dir, "gc", "-q")
+	assertParity(t, dir)
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          LineStats
+	}{
+		{"unchanged", "a\nb\n", "a\nb\n", LineStats{}},
+		{"new file", "", "a\nb\n", LineStats{Added: 2}},
+		{"deleted file", "a\nb\nc\n", "", LineStats{Removed: 3}},
+		{"one line changed", "a\nb\nc\n", "a\nB\nc\n", LineStats{Added: 1, Removed: 1}},
+		{"insert in middle", "a\nc\n", "a\nb\nc\n", LineStats{Added: 1}},
+		{"missing final newline", "a\nb\n", "a\nb", LineStats{Added: 1, Removed: 1}},
+		{"reordered", "a\nb\nc\n", "c\na\nb\n", LineStats{Added: 1, Removed: 1}},
+		{"binary", "a\n", "a\x00b\n", LineStats{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines([]byte(tt.before), []byte(tt.after)); got != tt.want {
+				t.Errorf("diffLines() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeRewrite(t *testing.T) {
+	var before, after strings.Builder
+	for i := range 5000 {
+		fmt.Fprintf(&before, "old %d\n", i)
+		fmt.Fprintf(&after, "new %d\n", i)
+	}
+	got := diffLines([]byte(before.String()), []byte(after.String()))
+	if got != (LineStats{Added: 5000, Removed: 5000}) {
+		t.Errorf("expected a full rewrite, got %+v", got)
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	out := "3\t1\tmain.go\n-\t-\timage.png\n0\t0\told.txt => new.txt\n10\t0\tdocs/README.md\n"
+	if got := parseNumstat(out); got != (LineStats{Added: 13, Removed: 1}) {
+		t.Errorf("parseNumstat() = %+v", got)
+	}
+}
+
+func TestNativeParityCommits(t *testing.T) {
+	dir := initRepo(t, "main")
+	start := strings.TrimSpace(gitCmd(t, dir, "rev-parse", "HEAD"))
+
+	writeFile(t, dir, "a.txt", "one\ntwo\n")
+	gitCmd(t, dir, "add", "a.txt")
+	gitCmd(t, dir, "commit", "-q", "-m", "Add a\n\nWith a body")
+	gitCmd(t, dir, "checkout", "-q", "-b", "topic")
+	writeFile(t, dir, "README.md", "changed\n")
+	gitCmd(t, dir, "mv", "a.txt", "b.txt")
+	gitCmd(t, dir, "commit", "-q", "-am", "Rename a\nacross two lines")
+	gitCmd(t, dir, "checkout", "-q", "main")
+	writeFile(t, dir, "c.txt", "c\n")
+	gitCmd(t, dir, "add", "c.txt")
+	gitCmd(t, dir, "commit", "-q", "-m", "Add c")
+	gitCmd(t, dir, "merge", "-q", "--no-ff", "-m", "Merge topic", "topic")
+
+	topic := strings.TrimSpace(gitCmd(t, dir, "rev-parse", "topic"))
+	for _, since := range []string{"", start, topic} {
+		want, err := ExecProvider{}.Commits(dir, since)
+		if err != nil {
+			t.Fatalf("exec commits failed: %v", err)
+		}
+		got, err := NativeProvider{}.Commits(dir, since)
+		if err != nil {
+			t.Fatalf("native commits failed: %v", err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Commits(%q) mismatch\nnative: %+v\nexec:   %+v", since, got, want)
+		}
+	}
+
+	commits, _ := NativeProvider{}.Commits(dir, start)
+	if len(commits) != 4 {
+		t.Fatalf("expected 4 commits since start, got %+v", commits)
+	}
+	if commits[0].Subject != "Merge topic" || !commits[0].Lines.IsZero() {
+		t.Errorf("expected the merge first without lines, got %+v", commits[0])
+	}
+}
+
+func TestNativeNotARepository(t *testing.T) {
+	if _, err := (NativeProvider{}).Status(t.TempDir()); err == nil {
+		t.Error("expected error outside a repository")
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	for backend, want := range map[string]Provider{"": ExecProvider{}, "exec": ExecProvider{}, "native": NativeProvider{}} {
+		got, err := NewProvider(backend, Options{})
+		if err != nil || got != want {
+			t.Errorf("NewProvider(%q) = %T, %v", backend, got, err)
+		}
+	}
+	if _, err := NewProvider("libgit2", Options{}); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	var rules ignoreRules
+	for _, line := range []string{"*.log", "!keep.log", "/root-only.txt", "build/", "docs/**/*.tmp", `\#literal`} {
+		if rule, ok := parseIgnoreLine(line, ""); ok {
+			rules = append(rules, rule)
+		}
+	}
+	if rule, ok := parseIgnoreLine("local.txt", "sub/"); ok {
+		rules = append(rules, rule)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"deep/dir/debug.log", false, true},
+		{"keep.log", false, false},
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"docs/a/b/x.tmp", false, true},
+		{"docs/x.tmp", false, true},
+		{"#literal", false, true},
+		{"sub/local.txt", false, true},
+		{"local.txt", false, false},
+	}
+	for _, tt := range tests {
+		if got := rules.ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestNativeParityLastCommitOptions(t *testing.T) {
+	dir := initRepo(t, "main")
+	dirtyAge := Options{DirtyAge: true}
+
+	// Clean: neither backend reads HEAD's details
+	assertParityWith(t, dir, dirtyAge)
+	assertParityWith(t, dir, Options{})
+	if status, _ := GetStatus(dir, dirtyAge); !status.LastCommitTime.IsZero() {
+		t.Errorf("expected no last commit on a clean tree, got %v", status.LastCommitTime)
+	}
+
+	writeFile(t, dir, "README.md", "changed\n")
+	assertParityWith(t, dir, dirtyAge)
+	if status, _ := GetStatus(dir, dirtyAge); status.LastCommitTime.IsZero() || status.LastCommitSubject != "initial" {
+		t.Errorf("expected the last commit on a dirty tree, got %+v", status)
+	}
+	if status, _ := GetStatus(dir, Options{}); !status.LastCommitTime.IsZero() {
+		t.Errorf("expected no last commit when not requested, got %v", status.LastCommitTime)
+	}
+}
+
+func TestNativeParityGlobalExcludes(t *testing.T) {
+	dir := initRepo(t, "main")
+	writeFile(t, dir, ".DS_Store", "finder\n")
+	writeFile(t, dir, "notes.bak", "old\n")
+	writeFile(t, dir, "kept.txt", "new\n")
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	if err := os.MkdirAll(filepath.Join(configHome, "git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, configHome, "git/ignore", ".DS_Store\n")
+	assertParity(t, dir)
+	if status, _ := (NativeProvider{}).Status(dir); status.Untracked != 2 {
+		t.Errorf("XDG ignore: untracked = %d, want 2", status.Untracked)
+	}
+
+	// core.excludesFile replaces the XDG default
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, home, ".gitconfig", "[core]\n\texcludesFile = ~/global-ignore\n")
+	writeFile(t, home, "global-ignore", "*.bak\n")
+	assertParity(t, dir)
+	if status, _ := (NativeProvider{}).Status(dir); status.Untracked != 2 {
+		t.Errorf("core.excludesFile: untracked = %d, want 2", status.Untracked)
+	}
+}
+
+func TestNativeWalksStopAtDeadline(t *testing.T) {
+	dir := initRepo(t, "main")
+	writeFile(t, dir, "new.txt", "untracked\n")
+	gitCmd(t, dir, "checkout", "-q", "-b", "feature")
+	gitCmd(t, dir, "commit", "-q", "--allow-empty", "-m", "feature")
+
+	r, err := openRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := openObjectStore(filepath.Join(r.commonDir, "objects"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.close()
+	idx, err := readIndex(filepath.Join(r.gitDir, "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := r.resolveRef("refs/heads/feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	main, err := r.resolveRef("refs/heads/main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := r.untracked(ctx, idx); !errors.Is(err, context.Canceled) {
+		t.Errorf("untracked: err = %v, want context.Canceled", err)
+	}
+	if _, _, err := aheadBehind(ctx, store, head, main); !errors.Is(err, context.Canceled) {
+		t.Errorf("aheadBehind: err = %v, want context.Canceled", err)
+	}
+	if _, err := exclusiveCommits(ctx, store, head, "", maxCommits); !errors.Is(err, context.Canceled) {
+		t.Errorf("exclusiveCommits: err = %v, want context.Canceled", err)
+	}
+	if base := mergeBase(ctx, store, head, main); base != "" {
+		t.Errorf("mergeBase = %q after the deadline, want none", base)
+	}
+}
+
+func TestNativeStatusPastDeadline(t *testing.T) {
+	dir := workingTreeRepo(t)
+	defer func(timeout time.Duration) { walkTimeout = timeout }(walkTimeout)
+	walkTimeout = 0
+
+	status, err := NativeProvider{allOptions}.Status(dir)
+	if err != nil {
+		t.Fatalf("expected a status despite the expired walks: %v", err)
+	}
+	if status.Head == "" || status.LastCommitSubject == "" {
+		t.Errorf("expected HEAD to be kept, got %+v", status)
+	}
+	if status.Untracked != 0 || status.DirtyFiles != 0 {
+		t.Errorf("expected the cut-short counts to be left out, got %+v", status)
+	}
+}
+
+func TestNativeSplitIndexFallsBack(t *testing.T) {
+	dir := workingTreeRepo(t)
+	gitCmd(t, dir, "update-index", "--split-index")
+	writeFile(t, dir, "after-split.txt", "new\n")
+	gitCmd(t, dir, "add", "after-split.txt")
+
+	idx := filepath.Join(dir, ".git", "index")
+	if _, err := readIndex(idx); !errors.Is(err, errUnsupportedIndex) {
+		t.Fatalf("expected a split index to be reported as unsupported, got %v", err)
+	}
+	assertParity(t, dir)
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello, world")
+	// Source size 12, target size 10: copy 5 bytes from offset 0, then insert " git!"
+	delta := []byte{12, 10, 0x90, 5, 5, ' ', 'g', 'i', 't', '!'}
+	got, err := applyDelta(base, delta)
+	if err != nil {
+		t.Fatalf("applyDelta failed: %v", err)
+	}
+	if string(got) != "hello git!" {
+		t.Errorf("expected 'hello git!', got %q", got)
+	}
+
+	if _, err := applyDelta([]byte("short"), delta); err == nil {
+		t.Error("expected error for mismatched base size")
+	}
+}
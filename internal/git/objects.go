@@ -0,0 +1,483 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Object types as stored in packfiles
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+// maxDeltaDepth guards against corrupt packs with cyclic deltas
+const maxDeltaDepth = 64
+
+// object is a decoded git object
+type object struct {
+	kind int
+	data []byte
+}
+
+// objectStore reads loose and packed objects from an objects directory
+type objectStore struct {
+	dir   string
+	packs []*packFile
+	cache map[string]*object
+}
+
+// openObjectStore indexes the packfiles under dir
+func openObjectStore(dir string) (*objectStore, error) {
+	store := &objectStore{dir: dir, cache: make(map[string]*object)}
+
+	idxFiles, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	for _, idxPath := range idxFiles {
+		pack, err := openPack(idxPath)
+		if err != nil {
+			store.close()
+			return nil, err
+		}
+		store.packs = append(store.packs, pack)
+	}
+
+	return store, nil
+}
+
+func (s *objectStore) close() {
+	for _, pack := range s.packs {
+		_ = pack.file.Close()
+	}
+}
+
+// read returns the object with the given hex id
+func (s *objectStore) read(oid string) (*object, error) {
+	if obj, ok := s.cache[oid]; ok {
+		return obj, nil
+	}
+
+	obj, err := s.readLoose(oid)
+	if errors.Is(err, os.ErrNotExist) {
+		obj, err = s.readPacked(oid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache[oid] = obj
+	return obj, nil
+}
+
+// readLoose reads a zlib-compressed object from objects/xx/yyyy...
+func (s *objectStore) readLoose(oid string) (*object, error) {
+	if len(oid) < 3 {
+		return nil, fmt.Errorf("invalid object id %q", oid)
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, oid[:2], oid[2:]))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	zr, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("object %s: %w", oid, err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("object %s: %w", oid, err)
+	}
+
+	// Header is "<type> <size>\0"
+	nul := bytes.IndexByte(raw, 0)
+	space := bytes.IndexByte(raw, ' ')
+	if nul < 0 || space < 0 || space > nul {
+		return nil, fmt.Errorf("object %s: malformed header", oid)
+	}
+
+	kind, ok := map[string]int{"commit": objCommit, "tree": objTree, "blob": objBlob, "tag": objTag}[string(raw[:space])]
+	if !ok {
+		return nil, fmt.Errorf("object %s: unknown type %q", oid, raw[:space])
+	}
+	return &object{kind: kind, data: raw[nul+1:]}, nil
+}
+
+// readPacked looks the object up in every pack index
+func (s *objectStore) readPacked(oid string) (*object, error) {
+	id, err := hex.DecodeString(oid)
+	if err != nil || len(id) != 20 {
+		return nil, fmt.Errorf("invalid object id %q", oid)
+	}
+
+	for _, pack := range s.packs {
+		if offset, ok := pack.find(id); ok {
+			return pack.readAt(s, offset, 0)
+		}
+	}
+	return nil, fmt.Errorf("object %s not found", oid)
+}
+
+// packFile is a packfile and its version 2 index
+type packFile struct {
+	file    *os.File
+	fanout  [256]uint32
+	ids     []byte // Sorted 20-byte object ids
+	offsets []uint64
+	cache   map[uint64]*object // Delta bases by offset
+}
+
+// openPack reads a pack index and opens the matching packfile
+func openPack(idxPath string) (*packFile, error) {
+	idx, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Version 2: magic, version, 256 fanout entries, ids, crcs, offsets, large offsets
+	if len(idx) < 8+256*4 || !bytes.Equal(idx[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(idx[4:8]) != 2 {
+		return nil, fmt.Errorf("%s: unsupported pack index version", idxPath)
+	}
+
+	pack := &packFile{cache: make(map[uint64]*object)}
+	for i := range pack.fanout {
+		pack.fanout[i] = binary.BigEndian.Uint32(idx[8+i*4:])
+	}
+	count := int(pack.fanout[255])
+
+	idsStart := 8 + 256*4
+	crcStart := idsStart + count*20
+	offsetStart := crcStart + count*4
+	largeStart := offsetStart + count*4
+	if len(idx) < largeStart {
+		return nil, fmt.Errorf("%s: truncated pack index", idxPath)
+	}
+
+	pack.ids = idx[idsStart:crcStart]
+	pack.offsets = make([]uint64, count)
+	for i := range count {
+		offset := binary.BigEndian.Uint32(idx[offsetStart+i*4:])
+		if offset&0x80000000 == 0 {
+			pack.offsets[i] = uint64(offset)
+			continue
+		}
+		large := largeStart + int(offset&0x7fffffff)*8
+		if len(idx) < large+8 {
+			return nil, fmt.Errorf("%s: truncated large offset table", idxPath)
+		}
+		pack.offsets[i] = binary.BigEndian.Uint64(idx[large:])
+	}
+
+	pack.file, err = os.Open(idxPath[:len(idxPath)-len(".idx")] + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// find returns the pack offset of an object id
+func (p *packFile) find(id []byte) (uint64, bool) {
+	lo := 0
+	if id[0] > 0 {
+		lo = int(p.fanout[id[0]-1])
+	}
+	hi := int(p.fanout[id[0]])
+
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.ids[(lo+i)*20:(lo+i)*20+20], id) >= 0
+	})
+	if i < hi && bytes.Equal(p.ids[i*20:i*20+20], id) {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+// readAt decodes the object at offset, resolving deltas
+func (p *packFile) readAt(store *objectStore, offset uint64, depth int) (*object, error) {
+	if obj, ok := p.cache[offset]; ok {
+		return obj, nil
+	}
+	if depth > maxDeltaDepth {
+		return nil, errors.New("delta chain too deep")
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(p.file, int64(offset), 1<<62))
+
+	// Type and size: 3 type bits and 4 size bits, then 7 size bits per byte
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	kind := int(c>>4) & 7
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+
+	var base *object
+	switch kind {
+	case objOfsDelta:
+		// Offset encoding adds one for each continuation byte
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		rel := uint64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return nil, err
+			}
+			rel = (rel+1)<<7 | uint64(c&0x7f)
+		}
+		if rel > offset {
+			return nil, errors.New("invalid delta base offset")
+		}
+		if base, err = p.readAt(store, offset-rel, depth+1); err != nil {
+			return nil, err
+		}
+	case objRefDelta:
+		id := make([]byte, 20)
+		if _, err := io.ReadFull(r, id); err != nil {
+			return nil, err
+		}
+		if base, err = store.read(hex.EncodeToString(id)); err != nil {
+			return nil, err
+		}
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &object{kind: kind, data: data}
+	if base != nil {
+		patched, err := applyDelta(base.data, data)
+		if err != nil {
+			return nil, err
+		}
+		obj = &object{kind: base.kind, data: patched}
+	}
+
+	// Trees and commits are delta bases for each other; blobs are rarely reread
+	if obj.kind != objBlob {
+		p.cache[offset] = obj
+	}
+	return obj, nil
+}
+
+// applyDelta rebuilds an object from its base and a git delta
+func applyDelta(base, delta []byte) ([]byte, error) {
+	srcSize, n := deltaSize(delta)
+	if n == 0 || srcSize != uint64(len(base)) {
+		return nil, errors.New("delta base size mismatch")
+	}
+	delta = delta[n:]
+	dstSize, n := deltaSize(delta)
+	if n == 0 {
+		return nil, errors.New("malformed delta")
+	}
+	delta = delta[n:]
+
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		if op&0x80 == 0 {
+			// Insert the next op bytes
+			if op == 0 || int(op) > len(delta) {
+				return nil, errors.New("malformed delta insert")
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+			continue
+		}
+
+		// Copy from base: present offset and size bytes are flagged in op
+		var offset, size uint64
+		for i := range 4 {
+			if op&(1<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errors.New("malformed delta copy")
+				}
+				offset |= uint64(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		for i := range 3 {
+			if op&(0x10<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errors.New("malformed delta copy")
+				}
+				size |= uint64(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		if size == 0 {
+			size = 0x10000
+		}
+		if offset+size > uint64(len(base)) {
+			return nil, errors.New("delta copy out of range")
+		}
+		out = append(out, base[offset:offset+size]...)
+	}
+
+	if uint64(len(out)) != dstSize {
+		return nil, errors.New("delta result size mismatch")
+	}
+	return out, nil
+}
+
+// deltaSize reads a little-endian base-128 size, returning bytes consumed (0 on error)
+func deltaSize(b []byte) (uint64, int) {
+	var size uint64
+	for i, c := range b {
+		size |= uint64(c&0x7f) << (7 * i)
+		if c&0x80 == 0 {
+			return size, i + 1
+		}
+		if i >= 9 {
+			break
+		}
+	}
+	return 0, 0
+}
+
+// commitInfo is the part of a commit the statusline needs
+type commitInfo struct {
+	tree    string
+	parents []string
+	time    int64  // Committer timestamp
+	author  string // Author name
+	subject string // First paragraph of the message, on one line
+}
+
+// readCommit parses a commit object
+func (s *objectStore) readCommit(oid string) (*commitInfo, error) {
+	obj, err := s.read(oid)
+	if err != nil {
+		return nil, err
+	}
+	if obj.kind != objCommit {
+		return nil, fmt.Errorf("object %s is not a commit", oid)
+	}
+
+	commit := &commitInfo{}
+	headers, message, _ := bytes.Cut(obj.data, []byte("\n\n"))
+	for _, line := range bytes.Split(headers, []byte("\n")) {
+		key, value, _ := bytes.Cut(line, []byte(" "))
+		switch string(key) {
+		case "tree":
+			commit.tree = string(value)
+		case "parent":
+			commit.parents = append(commit.parents, string(value))
+		case "author":
+			// "Name <email> 1700000000 +0100"
+			if name, _, ok := bytes.Cut(value, []byte(" <")); ok {
+				commit.author = string(name)
+			}
+		case "committer":
+			// "Name <email> 1700000000 +0100"
+			fields := bytes.Fields(value)
+			if len(fields) >= 2 {
+				commit.time, _ = strconv.ParseInt(string(fields[len(fields)-2]), 10, 64)
+			}
+		}
+	}
+
+	// Like git's %s: the first paragraph with its lines joined by spaces
+	message = bytes.TrimLeft(message, "\n")
+	paragraph, _, _ := bytes.Cut(message, []byte("\n\n"))
+	lines := strings.Split(strings.TrimSpace(string(paragraph)), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	commit.subject = strings.Join(lines, " ")
+	return commit, nil
+}
+
+// peel follows annotated tags to the object they point at
+func (s *objectStore) peel(oid string) (string, error) {
+	for range maxDeltaDepth {
+		obj, err := s.read(oid)
+		if err != nil {
+			return "", err
+		}
+		if obj.kind != objTag {
+			return oid, nil
+		}
+		target, _, _ := bytes.Cut(obj.data, []byte("\n"))
+		oid = string(bytes.TrimPrefix(target, []byte("object ")))
+	}
+	return "", errors.New("tag chain too deep")
+}
+
+// treeEntry is a file in a flattened tree
+type treeEntry struct {
+	oid  string
+	mode uint32
+}
+
+// flattenTree lists every non-tree entry under the tree oid by full path
+func (s *objectStore) flattenTree(oid, prefix string, out map[string]treeEntry) error {
+	obj, err := s.read(oid)
+	if err != nil {
+		return err
+	}
+	if obj.kind != objTree {
+		return fmt.Errorf("object %s is not a tree", oid)
+	}
+
+	// Entries are "<octal mode> <name>\0<20-byte id>"
+	data := obj.data
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+21 {
+			return fmt.Errorf("tree %s: malformed entry", oid)
+		}
+		mode, err := strconv.ParseUint(string(data[:space]), 8, 32)
+		if err != nil {
+			return fmt.Errorf("tree %s: %w", oid, err)
+		}
+		name := prefix + string(data[space+1:nul])
+		child := hex.EncodeToString(data[nul+1 : nul+21])
+		data = data[nul+21:]
+
+		if mode == modeTree {
+			if err := s.flattenTree(child, name+"/", out); err != nil {
+				return err
+			}
+			continue
+		}
+		out[name] = treeEntry{oid: child, mode: uint32(mode)}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package git
+
+import "fmt"
+
+// Provider reads branch and status information for the repository containing a directory
+type Provider interface {
+	Branch(dir string) (string, error)
+	Status(dir string) (*Status, error)
+	// Commits lists commits reachable from HEAD but not from the commit id
+	// since (all of HEAD's history when empty), newest first
+	Commits(dir, since string) ([]Commit, error)
+}
+
+// Options selects optional information a Provider collects
+type Options struct {
+	CompareBase bool   // Count commits and lines since the merge-base with BaseBranch
+	BaseBranch  string // Branch to compare against; empty tries main, then master
+	Stash       bool   // Count stash entries
+	Tag         bool   // Find the nearest tag reachable from HEAD (slow on big histories)
+	LineStats   bool   // Count lines added and removed in the working tree and index
+	LastCommit  bool   // Read HEAD's time, author and subject
+	DirtyAge    bool   // Read them only while the tree is dirty (to warn about old changes)
+}
+
+// lastCommit reports whether HEAD's details are wanted for a tree with dirty changes
+func (o Options) lastCommit(dirty int) bool {
+	return o.LastCommit || (o.DirtyAge && dirty > 0)
+}
+
+// ExecProvider runs the git command line
+type ExecProvider struct {
+	Options
+}
+
+// Branch returns the current branch name by running git rev-parse
+func (ExecProvider) Branch(dir string) (string, error) {
+	return GetBranch(dir)
+}
+
+// Status returns status information by running git status and friends
+func (p ExecProvider) Status(dir string) (*Status, error) {
+	status, err := GetStatus(dir, p.Options)
+	if err != nil {
+		return nil, err
+	}
+	if p.CompareBase && status.Head != "" {
+		// A missing base branch just leaves the comparison out
+		_ = compareBase(dir, p.BaseBranch, status)
+	}
+	return status, nil
+}
+
+// Commits lists commits by running git log
+func (ExecProvider) Commits(dir, since string) ([]Commit, error) {
+	return GetCommits(dir, since)
+}
+
+// NewProvider returns the backend with the given name: "exec" (default) or "native"
+func NewProvider(backend string, opts Options) (Provider, error) {
+	switch backend {
+	case "", "exec":
+		return ExecProvider{opts}, nil
+	case "native":
+		return NativeProvider{opts}, nil
+	default:
+		return nil, fmt.Errorf("unknown git backend %q", backend)
+	}
+}
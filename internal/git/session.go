@@ -0,0 +1,147 @@
+package git
+
+import (
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+// sessionFile is the store file holding where HEAD was when each session started
+const sessionFile = "git-sessions.json"
+
+// sessionLock serializes ledger updates across concurrent sessions
+const sessionLock = "git-sessions.lock"
+
+// Ledger and cache updates hold their lock for milliseconds; one older than
+// lockTTL was abandoned
+const (
+	lockTTL  = 5 * time.Second
+	lockWait = 500 * time.Millisecond
+)
+
+// sessionRetention drops sessions not seen in a while
+const sessionRetention = 30 * 24 * time.Hour
+
+// Commit is one commit in a repository's history
+type Commit struct {
+	ID      string    `json:"id"`
+	Subject string    `json:"subject"`
+	Time    time.Time `json:"time"` // Committer time
+	Lines   LineStats `json:"lines"`
+}
+
+// SessionStart records HEAD when a session was first seen in a repository,
+// with the commits last computed since then
+type SessionStart struct {
+	Head      string    `json:"head"` // Full commit id, empty for an unborn branch
+	StartedAt time.Time `json:"startedAt"`
+	SeenAt    time.Time `json:"seenAt"`
+	LastHead  string    `json:"lastHead,omitempty"` // HEAD that Commits was computed for
+	Commits   []Commit  `json:"commits,omitempty"`
+}
+
+// sessionLedger maps session ID → repository directory → start
+type sessionLedger map[string]map[string]*SessionStart
+
+func loadSessions() sessionLedger {
+	ledger := sessionLedger{}
+	if err := store.Load(sessionFile, &ledger); err != nil {
+		// Corrupt ledger: start over rather than failing the statusline
+		return sessionLedger{}
+	}
+	return ledger
+}
+
+// LoadSession returns the repositories recorded for a session by directory
+func LoadSession(sessionID string) map[string]*SessionStart {
+	return loadSessions()[sessionID]
+}
+
+// SessionCommits records head as the session's starting point in dir the
+// first time the session is seen there, and returns the commits created
+// since, newest first. Commits dated before the session started (e.g. after
+// switching to an older branch) are left out. Results are reused until HEAD
+// moves.
+func SessionCommits(provider Provider, sessionID, dir, head string, now time.Time) ([]Commit, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+
+	// Listing commits runs git, so it happens before taking the lock
+	start := loadSessions()[sessionID][dir]
+	var commits []Commit
+	switch {
+	case start == nil:
+	case start.LastHead == head && now.Sub(start.SeenAt) < time.Hour:
+		return start.Commits, nil // Nothing new; only touch the ledger occasionally
+	case start.LastHead == head:
+		commits = start.Commits
+	default:
+		var err error
+		if commits, err = sessionCommits(provider, dir, start); err != nil {
+			return nil, err
+		}
+	}
+
+	release, err := store.Lock(sessionLock, lockTTL, lockWait)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Reload: other sessions may have saved since
+	ledger := loadSessions()
+	repos := ledger[sessionID]
+	if repos == nil {
+		repos = map[string]*SessionStart{}
+		ledger[sessionID] = repos
+	}
+
+	entry := repos[dir]
+	switch {
+	case entry == nil && start == nil:
+		entry = &SessionStart{Head: head, StartedAt: now, LastHead: head}
+	case entry == nil:
+		entry = start
+	}
+	if start != nil {
+		entry.Commits = commits
+		entry.LastHead = head
+	}
+	entry.SeenAt = now
+	repos[dir] = entry
+
+	for id, sessionRepos := range ledger {
+		for key, s := range sessionRepos {
+			if now.Sub(s.SeenAt) > sessionRetention {
+				delete(sessionRepos, key)
+			}
+		}
+		if len(sessionRepos) == 0 {
+			delete(ledger, id)
+		}
+	}
+
+	if err := store.Save(sessionFile, ledger); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// sessionCommits lists the commits on HEAD since start, created after it
+func sessionCommits(provider Provider, dir string, start *SessionStart) ([]Commit, error) {
+	all, err := provider.Commits(dir, start.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit times have second precision
+	since := start.StartedAt.Truncate(time.Second)
+	var commits []Commit
+	for _, commit := range all {
+		if !commit.Time.Before(since) {
+			commits = append(commits, commit)
+		}
+	}
+	return commits, nil
+}
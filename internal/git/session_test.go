@@ -0,0 +1,131 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+func TestSessionCommits(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	dir := initRepo(t, "main")
+	head := func() string { return strings.TrimSpace(gitCmd(t, dir, "rev-parse", "HEAD")) }
+
+	// Commit times have second precision; start the session a little earlier
+	started := time.Now().Add(-time.Minute)
+	commits, err := SessionCommits(ExecProvider{}, "session-1", dir, head(), started)
+	if err != nil || len(commits) != 0 {
+		t.Fatalf("expected no commits at session start, got %+v (%v)", commits, err)
+	}
+
+	writeFile(t, dir, "a.txt", "one\ntwo\n")
+	gitCmd(t, dir, "add", "a.txt")
+	gitCmd(t, dir, "commit", "-q", "-m", "Add a")
+	writeFile(t, dir, "a.txt", "one\n")
+	gitCmd(t, dir, "commit", "-q", "-am", "Trim a")
+
+	commits, err = SessionCommits(ExecProvider{}, "session-1", dir, head(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "Trim a" || commits[1].Subject != "Add a" {
+		t.Fatalf("expected the two new commits newest first, got %+v", commits)
+	}
+	if commits[1].Lines != (LineStats{Added: 2}) || commits[0].Lines != (LineStats{Removed: 1}) {
+		t.Errorf("unexpected line counts: %+v", commits)
+	}
+
+	// Another session starting now sees none of them
+	commits, _ = SessionCommits(ExecProvider{}, "session-2", dir, head(), time.Now())
+	if len(commits) != 0 {
+		t.Errorf("expected no commits for a new session, got %+v", commits)
+	}
+
+	recorded := LoadSession("session-1")
+	if start := recorded[dir]; start == nil || len(start.Commits) != 2 {
+		t.Errorf("expected the session's commits to be recorded, got %+v", recorded)
+	}
+}
+
+func TestSessionCommitsIgnoresOlderHistory(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	dir := initRepo(t, "main")
+
+	// A branch with a commit dated long before the session
+	gitCmd(t, dir, "checkout", "-q", "-b", "old")
+	writeFile(t, dir, "old.txt", "old\n")
+	gitCmd(t, dir, "add", "old.txt")
+	cmd := gitCommand(dir, "commit", "-q", "-m", "Old work")
+	cmd.Env = append(cmd.Env, "GIT_AUTHOR_DATE=2020-01-01T00:00:00Z", "GIT_COMMITTER_DATE=2020-01-01T00:00:00Z")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("commit failed: %v\n%s", err, out)
+	}
+	gitCmd(t, dir, "checkout", "-q", "main")
+
+	mainHead := strings.TrimSpace(gitCmd(t, dir, "rev-parse", "HEAD"))
+	if _, err := SessionCommits(ExecProvider{}, "s", dir, mainHead, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	gitCmd(t, dir, "checkout", "-q", "old")
+	oldHead := strings.TrimSpace(gitCmd(t, dir, "rev-parse", "HEAD"))
+	commits, err := SessionCommits(NativeProvider{}, "s", dir, oldHead, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("expected commits older than the session to be ignored, got %+v", commits)
+	}
+}
+
+func TestSessionCommitsWithoutID(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	commits, err := SessionCommits(ExecProvider{}, "", "/repo", "abc", time.Now())
+	if err != nil || commits != nil {
+		t.Errorf("expected nothing without a session ID, got %+v (%v)", commits, err)
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	out := "\x00aaaa 1700000000 Fix the thing\n\n3\t1\tmain.go\n-\t-\tlogo.png\n\x00bbbb 1699990000 Merge branch 'x'\n\x00cccc 1699980000 \n\n1\t0\tREADME.md\n"
+	commits := parseLog(out)
+	if len(commits) != 3 {
+		t.Fatalf("expected 3 commits, got %+v", commits)
+	}
+	if commits[0].ID != "aaaa" || commits[0].Subject != "Fix the thing" || commits[0].Lines != (LineStats{Added: 3, Removed: 1}) {
+		t.Errorf("unexpected first commit: %+v", commits[0])
+	}
+	if !commits[0].Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected time: %v", commits[0].Time)
+	}
+	if !commits[1].Lines.IsZero() || commits[2].Subject != "" || commits[2].Lines.Added != 1 {
+		t.Errorf("unexpected commits: %+v", commits[1:])
+	}
+}
+
+func TestSessionCommitsConcurrentSessions(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	const sessions = 20
+	var wg sync.WaitGroup
+	for i := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := SessionCommits(ExecProvider{}, fmt.Sprintf("session-%d", i), "/repo", "abc", time.Now()); err != nil {
+				t.Errorf("session %d: %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range sessions {
+		if LoadSession(fmt.Sprintf("session-%d", i))["/repo"] == nil {
+			t.Errorf("session %d was lost", i)
+		}
+	}
+}
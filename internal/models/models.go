@@ -0,0 +1,119 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Capabilities describes the limits and pricing of a model
+type Capabilities struct {
+	ContextWindow        int // tokens
+	AutocompactThreshold int // context percentage at which Claude Code auto-compacts
+	Pricing              Pricing
+}
+
+// Pricing holds per-million-token prices in USD for a model family
+type Pricing struct {
+	Input      float64
+	Output     float64
+	CacheRead  float64
+	CacheWrite float64 // 5-minute cache write
+}
+
+const (
+	standardWindow = 200_000
+	longWindow     = 1_000_000
+
+	// Claude Code keeps a fixed token buffer free before compacting, so the
+	// percentage threshold is higher for larger windows
+	standardAutocompact = 80
+	longAutocompact     = 95
+)
+
+// registryEntry maps a model ID prefix to its capabilities
+type registryEntry struct {
+	prefix string
+	caps   Capabilities
+}
+
+var (
+	opusPricing       = Pricing{Input: 15, Output: 75, CacheRead: 1.50, CacheWrite: 18.75}
+	opusLatestPricing = Pricing{Input: 5, Output: 25, CacheRead: 0.50, CacheWrite: 6.25}
+	sonnetPricing     = Pricing{Input: 3, Output: 15, CacheRead: 0.30, CacheWrite: 3.75}
+	haikuPricing      = Pricing{Input: 1, Output: 5, CacheRead: 0.10, CacheWrite: 1.25}
+	haiku35Pricing    = Pricing{Input: 0.80, Output: 4, CacheRead: 0.08, CacheWrite: 1.00}
+)
+
+// registry is ordered most-specific first so prefix matching picks the right family
+var registry = []registryEntry{
+	{"claude-opus-4-6", standard(opusLatestPricing)},
+	{"claude-opus-4-5", standard(opusLatestPricing)},
+	{"claude-opus-4", standard(opusPricing)},
+	{"claude-sonnet-4", standard(sonnetPricing)},
+	{"claude-3-7-sonnet", standard(sonnetPricing)},
+	{"claude-haiku-4-5", standard(haikuPricing)},
+	{"claude-3-5-haiku", standard(haiku35Pricing)},
+}
+
+// defaultCapabilities is used for unknown models (Sonnet-class)
+var defaultCapabilities = standard(sonnetPricing)
+
+func standard(p Pricing) Capabilities {
+	return Capabilities{
+		ContextWindow:        standardWindow,
+		AutocompactThreshold: standardAutocompact,
+		Pricing:              p,
+	}
+}
+
+// normalize lowercases a model ID and splits off a variant suffix like "[1m]"
+func normalize(id string) (base, variant string) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if i := strings.Index(id, "["); i >= 0 {
+		return id[:i], strings.Trim(id[i:], "[]")
+	}
+	return id, ""
+}
+
+// longContextName matches a display name announcing the 1M-context variant,
+// e.g. "Sonnet 4.5 (1M context)"
+var longContextName = regexp.MustCompile(`(?i)\b1m context\b`)
+
+// Lookup returns the capabilities for a model ID, falling back to Sonnet-class
+// defaults. The 1M-context variant is recognized from a "[1m]" ID suffix or a
+// display name mentioning 1M context.
+func Lookup(id, displayName string) Capabilities {
+	base, variant := normalize(id)
+
+	caps := defaultCapabilities
+	for _, entry := range registry {
+		if strings.HasPrefix(base, entry.prefix) {
+			caps = entry.caps
+			break
+		}
+	}
+
+	if variant == "1m" || longContextName.MatchString(displayName) {
+		caps.ContextWindow = longWindow
+		caps.AutocompactThreshold = longAutocompact
+	}
+
+	return caps
+}
+
+// PricingFor returns the pricing for a model ID, falling back to Sonnet pricing
+func PricingFor(id string) Pricing {
+	return Lookup(id, "").Pricing
+}
+
+// CacheSavings estimates the USD saved by prompt caching compared to paying
+// the full input price for every cached token. Cache writes cost more than
+// plain input, so heavy cache churn can make this negative.
+func CacheSavings(id string, readTokens, writeTokens int) float64 {
+	p := PricingFor(id)
+
+	uncachedCost := float64(readTokens+writeTokens) * p.Input
+	cachedCost := float64(readTokens)*p.CacheRead + float64(writeTokens)*p.CacheWrite
+
+	return (uncachedCost - cachedCost) / 1_000_000
+}
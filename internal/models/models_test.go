@@ -0,0 +1,79 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPricingFor(t *testing.T) {
+	tests := []struct {
+		id        string
+		wantInput float64
+	}{
+		{"claude-opus-4-1-20250805", 15},
+		{"claude-opus-4-5-20251101", 5},
+		{"claude-opus-4-6", 5},
+		{"claude-sonnet-4-5-20250929", 3},
+		{"claude-sonnet-4-5-20250929[1m]", 3},
+		{"claude-haiku-4-5-20251001", 1},
+		{"claude-3-5-haiku-20241022", 0.80},
+		{"some-unknown-model", 3},
+		{"", 3},
+	}
+
+	for _, tt := range tests {
+		got := PricingFor(tt.id)
+		if got.Input != tt.wantInput {
+			t.Errorf("PricingFor(%q).Input = %v, want %v", tt.id, got.Input, tt.wantInput)
+		}
+	}
+}
+
+func TestCacheSavings(t *testing.T) {
+	// Sonnet: 1M read tokens cost $0.30 instead of $3.00
+	got := CacheSavings("claude-sonnet-4-5", 1_000_000, 0)
+	if math.Abs(got-2.70) > 1e-9 {
+		t.Errorf("expected $2.70 saved, got %f", got)
+	}
+
+	// Pure cache writes cost more than uncached input
+	got = CacheSavings("claude-sonnet-4-5", 0, 1_000_000)
+	if math.Abs(got-(-0.75)) > 1e-9 {
+		t.Errorf("expected -$0.75 for cache writes only, got %f", got)
+	}
+
+	if got := CacheSavings("claude-sonnet-4-5", 0, 0); got != 0 {
+		t.Errorf("expected 0 savings without cache activity, got %f", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            string
+		displayName   string
+		wantWindow    int
+		wantThreshold int
+	}{
+		{"standard sonnet", "claude-sonnet-4-5-20250929", "Sonnet 4.5", 200_000, 80},
+		{"1m suffix", "claude-sonnet-4-5-20250929[1m]", "", 1_000_000, 95},
+		{"1m suffix uppercase", "claude-sonnet-4-5[1M]", "", 1_000_000, 95},
+		{"1m display name", "claude-sonnet-4-5", "Sonnet 4.5 (1M context)", 1_000_000, 95},
+		{"1m inside a word", "claude-haiku-4-5", "Haiku 1mini", 200_000, 80},
+		{"1m without context", "claude-sonnet-4-5", "Sonnet 4.5 1M", 200_000, 80},
+		{"opus", "claude-opus-4-6", "Opus 4.6", 200_000, 80},
+		{"unknown", "some-future-model", "", 200_000, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := Lookup(tt.id, tt.displayName)
+			if caps.ContextWindow != tt.wantWindow {
+				t.Errorf("ContextWindow = %d, want %d", caps.ContextWindow, tt.wantWindow)
+			}
+			if caps.AutocompactThreshold != tt.wantThreshold {
+				t.Errorf("AutocompactThreshold = %d, want %d", caps.AutocompactThreshold, tt.wantThreshold)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package oauth
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+// cacheFile is the store file holding the last usage response
+const cacheFile = "usage-cache.json"
+
+// OfflineEnv forces offline mode when set to a true value (e.g. "1")
+const OfflineEnv = "CC_HUD_GO_OFFLINE"
+
+// ErrOffline is returned instead of making a request in offline mode
+var ErrOffline = errors.New("offline mode: network requests disabled")
+
+// OfflineFromEnv reports whether OfflineEnv requests offline mode
+func OfflineFromEnv() bool {
+	offline, _ := strconv.ParseBool(os.Getenv(OfflineEnv))
+	return offline
+}
+
+const (
+	// minBackoff is the wait after the first failed fetch
+	minBackoff = 30 * time.Second
+	// maxBackoff caps the exponential backoff
+	maxBackoff = 15 * time.Minute
+	// offlineProbe is how long detected offline mode skips the network before
+	// a single request checks whether the API is reachable again
+	offlineProbe = time.Hour
+)
+
+// CacheEntry is the persisted state of the usage cache
+type CacheEntry struct {
+	Usage           *UsageResponse `json:"usage,omitempty"`
+	FetchedAt       time.Time      `json:"fetchedAt"`
+	Failures        int            `json:"failures"`
+	NetworkFailures int            `json:"networkFailures"` // Consecutive failures to reach the API at all (DNS, connect, timeout)
+	NextAttempt     time.Time      `json:"nextAttempt"`
+	FailedAt        time.Time      `json:"failedAt"` // Time of the last failure
+	LastError       string         `json:"lastError,omitempty"`
+}
+
+// CachedUsage is a usage response together with its freshness
+type CachedUsage struct {
+	Usage           *UsageResponse
+	FetchedAt       time.Time
+	Stale           bool // Served from cache because a refresh failed or is backing off
+	NetworkFailures int  // Consecutive failures to reach the API
+}
+
+// Fresh reports whether the entry holds usage younger than ttl
+func (e *CacheEntry) Fresh(ttl time.Duration, now time.Time) bool {
+	return e.Usage != nil && now.Sub(e.FetchedAt) < ttl
+}
+
+// BackingOff reports whether a previous failure forbids fetching yet
+func (e *CacheEntry) BackingOff(now time.Time) bool {
+	return now.Before(e.NextAttempt)
+}
+
+// AutoOffline reports whether after consecutive network failures (0 = never)
+// switched to offline mode. It lasts offlineProbe from the last failure.
+func (e *CacheEntry) AutoOffline(after int, now time.Time) bool {
+	return after > 0 && e.NetworkFailures >= after && now.Sub(e.FailedAt) < offlineProbe
+}
+
+// DetectedOffline reports whether the usage cache records enough consecutive
+// network failures for offline mode (see CacheEntry.AutoOffline)
+func DetectedOffline(after int, now time.Time) bool {
+	if after <= 0 {
+		return false
+	}
+	entry, err := LoadCache()
+	return err == nil && entry.AutoOffline(after, now)
+}
+
+// recordSuccess stores a freshly fetched response and clears the backoff
+func (e *CacheEntry) recordSuccess(usage *UsageResponse, now time.Time) {
+	e.Usage = usage
+	e.FetchedAt = now
+	e.Failures = 0
+	e.NetworkFailures = 0
+	e.NextAttempt = time.Time{}
+	e.FailedAt = time.Time{}
+	e.LastError = ""
+}
+
+// recordFailure schedules the next attempt with exponential backoff,
+// honoring Retry-After on rate limited (429) responses
+func (e *CacheEntry) recordFailure(err error, now time.Time) {
+	e.Failures++
+	e.FailedAt = now
+	e.LastError = err.Error()
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		e.NetworkFailures++
+	} else {
+		e.NetworkFailures = 0
+	}
+
+	backoff := minBackoff << min(e.Failures-1, 10)
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusTooManyRequests && statusErr.RetryAfter > backoff {
+		backoff = statusErr.RetryAfter
+	}
+
+	e.NextAttempt = now.Add(backoff)
+}
+
+// result converts the entry into a CachedUsage
+func (e *CacheEntry) result(stale bool) *CachedUsage {
+	return &CachedUsage{Usage: e.Usage, FetchedAt: e.FetchedAt, Stale: stale, NetworkFailures: e.NetworkFailures}
+}
+
+// LoadCache reads the usage cache from the local store
+func LoadCache() (*CacheEntry, error) {
+	entry := &CacheEntry{}
+	if err := store.Load(cacheFile, entry); err != nil {
+		return &CacheEntry{}, err
+	}
+	return entry, nil
+}
+
+// SaveCache writes the usage cache to the local store
+func SaveCache(entry *CacheEntry) error {
+	return store.Save(cacheFile, entry)
+}
+
+// FetchUsageCached returns usage from the on-disk cache when it is younger
+// than ttl, otherwise calls fetch. Failures back off exponentially; while
+// backing off or after a failure the last known value is returned marked stale.
+func FetchUsageCached(ttl time.Duration, fetch func() (*UsageResponse, error), now time.Time) (*CachedUsage, error) {
+	entry, err := LoadCache()
+	if err != nil {
+		// Corrupt cache: start over
+		entry = &CacheEntry{}
+	}
+
+	if entry.Fresh(ttl, now) {
+		return entry.result(false), nil
+	}
+
+	if entry.BackingOff(now) {
+		if entry.Usage == nil {
+			return nil, errors.New("usage fetch backing off: " + entry.LastError)
+		}
+		return entry.result(true), nil
+	}
+
+	usage, err := fetch()
+	if errors.Is(err, ErrOffline) {
+		// Offline is a choice, not a failure: keep the backoff state untouched
+		if entry.Usage == nil {
+			return nil, err
+		}
+		return entry.result(true), nil
+	}
+	if err != nil {
+		entry.recordFailure(err, now)
+		_ = SaveCache(entry)
+		if entry.Usage == nil {
+			return nil, err
+		}
+		return entry.result(true), nil
+	}
+
+	entry.recordSuccess(usage, now)
+	_ = SaveCache(entry) // An unwritable cache only costs an extra fetch next time
+	return entry.result(false), nil
+}
+
+// PeekUsage returns cached usage without touching the network, and whether
+// a refresh is due (the cache is past ttl and not backing off). The usage
+// is nil when nothing has been cached yet, and only marked stale once a
+// refresh has failed.
+func PeekUsage(ttl time.Duration, now time.Time) (*CachedUsage, bool) {
+	entry, err := LoadCache()
+	if err != nil {
+		return nil, true
+	}
+
+	due := !entry.Fresh(ttl, now) && !entry.BackingOff(now)
+	if entry.Usage == nil {
+		return nil, due
+	}
+	return entry.result(entry.Failures > 0), due
+}
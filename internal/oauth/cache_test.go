@@ -0,0 +1,200 @@
+package oauth
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+var cacheBase = time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)
+
+func sampleUsage(fiveHour float64) *UsageResponse {
+	usage := &UsageResponse{Buckets: map[string]UsageBucket{
+		"five_hour":      {Utilization: fiveHour, ResetsAt: cacheBase.Add(time.Hour)},
+		"seven_day":      {Utilization: 40},
+		"seven_day_opus": {Utilization: 70},
+	}}
+	usage.FiveHour = usage.Buckets["five_hour"]
+	usage.SevenDay = usage.Buckets["seven_day"]
+	return usage
+}
+
+// countingFetch returns a fetch func that records how often it was called
+func countingFetch(usage *UsageResponse, err error, calls *int) func() (*UsageResponse, error) {
+	return func() (*UsageResponse, error) {
+		*calls++
+		return usage, err
+	}
+}
+
+func TestFetchUsageCachedServesFreshCache(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	calls := 0
+
+	first, err := FetchUsageCached(time.Minute, countingFetch(sampleUsage(10), nil, &calls), cacheBase)
+	if err != nil || first.Stale {
+		t.Fatalf("expected fresh fetch, got %+v, %v", first, err)
+	}
+
+	second, err := FetchUsageCached(time.Minute, countingFetch(sampleUsage(20), nil, &calls), cacheBase.Add(30*time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected cache hit within TTL, fetch called %d times", calls)
+	}
+	if second.Usage.FiveHour.Utilization != 10 {
+		t.Errorf("expected cached value, got %v", second.Usage.FiveHour.Utilization)
+	}
+	if second.Usage.Buckets["seven_day_opus"].Utilization != 70 {
+		t.Error("expected buckets to survive the cache round trip")
+	}
+
+	third, err := FetchUsageCached(time.Minute, countingFetch(sampleUsage(20), nil, &calls), cacheBase.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || third.Usage.FiveHour.Utilization != 20 {
+		t.Errorf("expected refetch after TTL, calls=%d value=%v", calls, third.Usage.FiveHour.Utilization)
+	}
+}
+
+func TestFetchUsageCachedStaleOnFailure(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	calls := 0
+
+	if _, err := FetchUsageCached(time.Minute, countingFetch(sampleUsage(10), nil, &calls), cacheBase); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Expired cache + failing fetch → last known value, marked stale
+	failing := countingFetch(nil, errors.New("network down"), &calls)
+	result, err := FetchUsageCached(time.Minute, failing, cacheBase.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("expected stale fallback, got error: %v", err)
+	}
+	if !result.Stale || result.Usage.FiveHour.Utilization != 10 {
+		t.Errorf("expected stale cached value, got %+v", result)
+	}
+
+	// Within the backoff window no fetch is attempted
+	calls = 0
+	result, err = FetchUsageCached(time.Minute, failing, cacheBase.Add(2*time.Minute+10*time.Second))
+	if err != nil || !result.Stale {
+		t.Fatalf("expected stale value while backing off, got %+v, %v", result, err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no fetch during backoff, got %d", calls)
+	}
+}
+
+func TestFetchUsageCachedNoCacheFailure(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	calls := 0
+
+	if _, err := FetchUsageCached(time.Minute, countingFetch(nil, errors.New("boom"), &calls), cacheBase); err == nil {
+		t.Error("expected error without any cached value")
+	}
+}
+
+func TestRecordFailureBackoff(t *testing.T) {
+	entry := &CacheEntry{}
+
+	entry.recordFailure(errors.New("fail"), cacheBase)
+	if got := entry.NextAttempt.Sub(cacheBase); got != minBackoff {
+		t.Errorf("first backoff = %v, want %v", got, minBackoff)
+	}
+
+	entry.recordFailure(errors.New("fail"), cacheBase)
+	if got := entry.NextAttempt.Sub(cacheBase); got != 2*minBackoff {
+		t.Errorf("second backoff = %v, want %v", got, 2*minBackoff)
+	}
+
+	for i := 0; i < 20; i++ {
+		entry.recordFailure(errors.New("fail"), cacheBase)
+	}
+	if got := entry.NextAttempt.Sub(cacheBase); got != maxBackoff {
+		t.Errorf("backoff should cap at %v, got %v", maxBackoff, got)
+	}
+
+	// 429 with a longer Retry-After wins
+	entry = &CacheEntry{}
+	entry.recordFailure(&StatusError{Code: http.StatusTooManyRequests, RetryAfter: 10 * time.Minute}, cacheBase)
+	if got := entry.NextAttempt.Sub(cacheBase); got != 10*time.Minute {
+		t.Errorf("expected Retry-After to be honored, got %v", got)
+	}
+
+	// Success clears the backoff
+	entry.recordSuccess(sampleUsage(5), cacheBase)
+	if entry.Failures != 0 || entry.BackingOff(cacheBase) {
+		t.Error("expected success to reset backoff")
+	}
+}
+
+func TestPeekUsage(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	if usage, due := PeekUsage(time.Minute, cacheBase); usage != nil || !due {
+		t.Fatalf("expected empty cache to be due for refresh, got %+v, %v", usage, due)
+	}
+
+	calls := 0
+	if _, err := FetchUsageCached(time.Minute, countingFetch(sampleUsage(10), nil, &calls), cacheBase); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, due := PeekUsage(time.Minute, cacheBase.Add(30*time.Second))
+	if usage == nil || usage.Stale || due {
+		t.Errorf("expected fresh cached usage, got %+v, due=%v", usage, due)
+	}
+
+	// Past ttl the old value is still shown normally while a refresh is due
+	usage, due = PeekUsage(time.Minute, cacheBase.Add(2*time.Minute))
+	if usage == nil || usage.Stale || !due {
+		t.Errorf("expected cached usage due for refresh, got %+v, due=%v", usage, due)
+	}
+
+	// A failed refresh backs off, so peeking should not ask for another one
+	failAt := cacheBase.Add(2 * time.Minute)
+	if _, err := FetchUsageCached(time.Minute, countingFetch(nil, errors.New("boom"), &calls), failAt); err != nil {
+		t.Fatal(err)
+	}
+	usage, due = PeekUsage(time.Minute, failAt.Add(time.Second))
+	if due {
+		t.Error("expected no refresh while backing off")
+	}
+	if usage == nil || !usage.Stale {
+		t.Errorf("expected usage marked stale after a failed refresh, got %+v", usage)
+	}
+	if calls != 2 {
+		t.Errorf("expected PeekUsage never to fetch, got %d fetches", calls)
+	}
+}
+
+func TestRecordFailureCountsNetworkFailures(t *testing.T) {
+	entry := &CacheEntry{}
+	netErr := &url.Error{Op: "Get", URL: "https://api.anthropic.com", Err: &net.OpError{Op: "dial", Err: errors.New("no route to host")}}
+
+	entry.recordFailure(netErr, cacheBase)
+	entry.recordFailure(netErr, cacheBase)
+	if entry.NetworkFailures != 2 {
+		t.Errorf("expected 2 network failures, got %d", entry.NetworkFailures)
+	}
+
+	// An error response means the network works
+	entry.recordFailure(&StatusError{Code: http.StatusInternalServerError}, cacheBase)
+	if entry.NetworkFailures != 0 {
+		t.Errorf("expected network failures reset by an API response, got %d", entry.NetworkFailures)
+	}
+
+	entry.recordFailure(netErr, cacheBase)
+	entry.recordSuccess(sampleUsage(10), cacheBase)
+	if entry.NetworkFailures != 0 {
+		t.Errorf("expected network failures reset by success, got %d", entry.NetworkFailures)
+	}
+}
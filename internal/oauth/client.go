@@ -0,0 +1,128 @@
+package oauth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds each request when no timeout is configured
+const defaultTimeout = 5 * time.Second
+
+// Options configures how credentials are found and how the API is reached
+type Options struct {
+	CredentialCommand string            // Shell command printing a token or credentials JSON
+	BaseURL           string            // API base URL (default https://api.anthropic.com)
+	TokenURL          string            // OAuth token endpoint (default Anthropic console)
+	ProxyURL          string            // Proxy for all requests; empty uses HTTPS_PROXY and friends
+	CAFile            string            // PEM bundle trusted in addition to the system roots
+	Timeout           time.Duration     // Per-request timeout (default 5s)
+	Headers           map[string]string // Extra headers sent with usage API requests
+	Offline           bool              // Never touch the network; FetchUsage returns ErrOffline
+	OfflineAfter      int               // Consecutive network failures that switch to offline mode (0 = never)
+
+	// NoRefresh keeps expired access tokens from being exchanged using Claude
+	// Code's refresh token. The exchange is on by default; if the server
+	// rotates refresh tokens, the one Claude Code stored stops working and it
+	// must log in again, which is what this opts out of.
+	NoRefresh bool
+
+	// DialContext replaces the transport's dialer (used by tests)
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// usageURL returns the usage endpoint under the configured base URL
+func (o Options) usageURL() string {
+	base := o.BaseURL
+	if base == "" {
+		base = defaultBaseURL
+	}
+	return strings.TrimRight(base, "/") + usagePath
+}
+
+// tokenURL returns the configured token endpoint
+func (o Options) tokenURL() string {
+	if o.TokenURL != "" {
+		return o.TokenURL
+	}
+	return defaultTokenURL
+}
+
+// NewClient builds an HTTP client honoring the proxy, CA bundle, timeout and
+// extra usage API headers in opts
+func NewClient(opts Options) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.DialContext != nil {
+		transport.DialContext = opts.DialContext
+	}
+
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", opts.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if len(opts.Headers) > 0 {
+		usage, err := url.Parse(opts.usageURL())
+		if err != nil {
+			return nil, fmt.Errorf("invalid base URL: %w", err)
+		}
+		roundTripper = &headerTransport{base: transport, headers: opts.Headers, usage: usage}
+	}
+
+	return &http.Client{Transport: roundTripper, Timeout: timeout}, nil
+}
+
+// headerTransport adds fixed headers to usage API requests
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+	usage   *url.URL // Usage endpoint; other requests (token refresh) pass through
+}
+
+// RoundTrip sends req with the configured headers when it targets the usage
+// endpoint. The bearer token is never replaced.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.usage.Host || req.URL.Path != t.usage.Path {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,286 @@
+package oauth
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+// usageHandler serves the fixture and records the last request
+func usageHandler(t *testing.T, last **http.Request) http.HandlerFunc {
+	t.Helper()
+	fixture, err := os.ReadFile("testdata/usage.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		*last = r
+		_, _ = w.Write(fixture)
+	}
+}
+
+// withToken makes the env provider supply a token without touching real credentials
+func withToken(t *testing.T) {
+	t.Helper()
+	t.Setenv(store.DirEnv, t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	t.Setenv(TokenEnv, "test-token")
+}
+
+func TestFetchUsageBaseURLAndHeaders(t *testing.T) {
+	withToken(t)
+
+	var last *http.Request
+	server := httptest.NewServer(usageHandler(t, &last))
+	defer server.Close()
+
+	usage, err := FetchUsage(Options{
+		BaseURL: server.URL + "/",
+		Headers: map[string]string{"X-Gateway-Key": "corp-123"},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("FetchUsage failed: %v", err)
+	}
+	if usage.FiveHour.Utilization == 0 {
+		t.Error("expected usage from fixture")
+	}
+
+	if last.URL.Path != usagePath {
+		t.Errorf("expected request to %s, got %s", usagePath, last.URL.Path)
+	}
+	if got := last.Header.Get("X-Gateway-Key"); got != "corp-123" {
+		t.Errorf("expected extra header, got %q", got)
+	}
+	if got := last.Header.Get("Authorization"); got != "Bearer test-token" {
+		t.Errorf("expected bearer token, got %q", got)
+	}
+}
+
+func TestHeadersOnlyOnUsageRequests(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	t.Setenv(TokenEnv, "")
+	server := newAuthServer(t)
+
+	var tokenHeaders, usageHeaders http.Header
+	server.Config.Handler = recordHeaders(server.Config.Handler, map[string]*http.Header{
+		"/v1/oauth/token": &tokenHeaders,
+		usagePath:         &usageHeaders,
+	})
+
+	expiresAt := time.Now().Add(-time.Hour).UnixMilli()
+	_, err := FetchUsage(Options{
+		CredentialCommand: fmt.Sprintf(`echo '{"accessToken":"expired","refreshToken":"refresh-1","expiresAt":%d}'`, expiresAt),
+		BaseURL:           server.URL,
+		TokenURL:          server.URL + "/v1/oauth/token",
+		Headers:           map[string]string{"X-Gateway-Key": "corp-123", "authorization": "Bearer gateway"},
+	})
+	if err != nil {
+		t.Fatalf("FetchUsage failed: %v", err)
+	}
+
+	if got := tokenHeaders.Get("X-Gateway-Key"); got != "" {
+		t.Errorf("expected no extra header on the token request, got %q", got)
+	}
+	if got := usageHeaders.Get("X-Gateway-Key"); got != "corp-123" {
+		t.Errorf("expected extra header on the usage request, got %q", got)
+	}
+	if got := usageHeaders.Get("Authorization"); got != "Bearer fresh-1" {
+		t.Errorf("expected the bearer token to be kept, got %q", got)
+	}
+}
+
+// recordHeaders wraps next, saving the request headers for each listed path
+func recordHeaders(next http.Handler, paths map[string]*http.Header) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if dst, ok := paths[r.URL.Path]; ok {
+			*dst = r.Header.Clone()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestFetchUsageThroughProxy(t *testing.T) {
+	withToken(t)
+
+	var last *http.Request
+	proxy := httptest.NewServer(usageHandler(t, &last))
+	defer proxy.Close()
+
+	// The API host doesn't resolve; only the proxy can answer
+	_, err := FetchUsage(Options{BaseURL: "http://api.example.invalid", ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("FetchUsage via proxy failed: %v", err)
+	}
+	if last.URL.Host != "api.example.invalid" {
+		t.Errorf("expected proxied request for api.example.invalid, got %q", last.URL.Host)
+	}
+}
+
+func TestFetchUsageCustomCA(t *testing.T) {
+	withToken(t)
+
+	var last *http.Request
+	server := httptest.NewTLSServer(usageHandler(t, &last))
+	defer server.Close()
+
+	// Without the CA the self-signed certificate is rejected
+	if _, err := FetchUsage(Options{BaseURL: server.URL}); err == nil {
+		t.Fatal("expected TLS verification to fail without CA file")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := FetchUsage(Options{BaseURL: server.URL, CAFile: caFile}); err != nil {
+		t.Fatalf("expected CA file to be trusted: %v", err)
+	}
+}
+
+func TestNewClientErrors(t *testing.T) {
+	badCA := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(badCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]Options{
+		"missing CA file": {CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"invalid CA file": {CAFile: badCA},
+		"invalid proxy":   {ProxyURL: "http://[::1"},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewClient(opts); err == nil {
+				t.Error("expected NewClient to fail")
+			}
+		})
+	}
+}
+
+func TestNewClientTimeout(t *testing.T) {
+	client, err := NewClient(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != defaultTimeout {
+		t.Errorf("expected default timeout %v, got %v", defaultTimeout, client.Timeout)
+	}
+
+	client, _ = NewClient(Options{Timeout: 2 * time.Second})
+	if client.Timeout != 2*time.Second {
+		t.Errorf("expected configured timeout, got %v", client.Timeout)
+	}
+}
+
+func TestOfflineNeverDials(t *testing.T) {
+	withToken(t)
+
+	dials := 0
+	opts := Options{
+		BaseURL: "http://api.example.invalid",
+		Offline: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials++
+			return nil, errors.New("dial attempted")
+		},
+	}
+	fetch := func() (*UsageResponse, error) { return FetchUsage(opts) }
+
+	// Nothing cached yet: offline reports an error without dialing
+	if _, err := FetchUsageCached(time.Minute, fetch, cacheBase); !errors.Is(err, ErrOffline) {
+		t.Errorf("expected ErrOffline, got %v", err)
+	}
+
+	// With a cached value, offline serves it as stale without dialing
+	calls := 0
+	if _, err := FetchUsageCached(time.Minute, countingFetch(sampleUsage(42), nil, &calls), cacheBase); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := FetchUsageCached(time.Minute, fetch, cacheBase.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("expected cached usage offline, got %v", err)
+	}
+	if !cached.Stale || cached.Usage.FiveHour.Utilization != 42 {
+		t.Errorf("expected stale cached usage, got %+v", cached)
+	}
+
+	if dials != 0 {
+		t.Errorf("expected no dials in offline mode, got %d", dials)
+	}
+
+	// Offline is not a failure, so it must not start a backoff
+	entry, _ := LoadCache()
+	if entry.Failures != 0 || entry.BackingOff(cacheBase.Add(time.Hour)) {
+		t.Errorf("expected no failure recorded offline, got %+v", entry)
+	}
+
+	// Sanity check that the dial hook is wired up
+	opts.Offline = false
+	if _, err := FetchUsage(opts); err == nil {
+		t.Error("expected dial error when online")
+	}
+	if dials == 0 {
+		t.Error("expected the dial hook to be used when online")
+	}
+}
+
+func TestDetectedOfflineNeverDials(t *testing.T) {
+	withToken(t)
+
+	// Three network failures in a row switched to offline mode a minute ago
+	if err := SaveCache(&CacheEntry{
+		Usage:           sampleUsage(42),
+		FetchedAt:       cacheBase.Add(-time.Hour),
+		Failures:        3,
+		NetworkFailures: 3,
+		FailedAt:        time.Now().Add(-time.Minute),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	dials := 0
+	opts := Options{
+		BaseURL:      "http://api.example.invalid",
+		OfflineAfter: 3,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials++
+			return nil, errors.New("dial attempted")
+		},
+	}
+	fetch := func() (*UsageResponse, error) { return FetchUsage(opts) }
+
+	cached, err := FetchUsageCached(time.Minute, fetch, time.Now())
+	if err != nil {
+		t.Fatalf("expected cached usage offline, got %v", err)
+	}
+	if !cached.Stale || cached.Usage.FiveHour.Utilization != 42 {
+		t.Errorf("expected stale cached usage, got %+v", cached)
+	}
+	if dials != 0 {
+		t.Errorf("expected no dials once offline was detected, got %d", dials)
+	}
+
+	// Offline mode lasts until the probe interval has passed
+	entry, _ := LoadCache()
+	if entry.AutoOffline(3, entry.FailedAt.Add(offlineProbe)) {
+		t.Error("expected a probe to be allowed after offlineProbe")
+	}
+	if entry.AutoOffline(0, time.Now()) {
+		t.Error("expected detection to be off with offlineAfter 0")
+	}
+}
@@ -0,0 +1,203 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// TokenEnv is the environment variable holding an OAuth access token
+const TokenEnv = "CLAUDE_CODE_OAUTH_TOKEN"
+
+// keychainService is the service name Claude Code stores credentials under
+const keychainService = "Claude Code-credentials"
+
+// Credentials are the OAuth credentials Claude Code stores after login
+type Credentials struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time // Zero when unknown
+}
+
+// Provider retrieves credentials from a single source
+type Provider interface {
+	Name() string
+	Credentials() (*Credentials, error)
+}
+
+// Providers returns the credential sources in the order they are tried:
+// environment, custom command, Claude Code's credentials file, OS keychain.
+// On macOS the keychain comes before the file: it is where Claude Code keeps
+// credentials there, so a file left behind by another setup can't shadow it.
+func Providers(opts Options) []Provider {
+	providers := []Provider{envProvider{}}
+	if opts.CredentialCommand != "" {
+		providers = append(providers, commandProvider{name: "command", args: shellCommand(opts.CredentialCommand)})
+	}
+
+	file := fileProvider{path: credentialsPath()}
+	args := keychainCommand()
+	if runtime.GOOS == "darwin" {
+		return append(providers, commandProvider{name: "keychain", args: args}, file)
+	}
+	providers = append(providers, file)
+	if args != nil {
+		providers = append(providers, commandProvider{name: "keychain", args: args})
+	}
+	return providers
+}
+
+// ResolveCredentials tries each provider in turn and returns the first
+// credentials found along with the name of the provider that supplied them
+func ResolveCredentials(providers []Provider) (*Credentials, string, error) {
+	var errs []error
+	for _, provider := range providers {
+		creds, err := provider.Credentials()
+		if err == nil {
+			return creds, provider.Name(), nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+	return nil, "", fmt.Errorf("no OAuth credentials found: %w", errors.Join(errs...))
+}
+
+// GetAccessToken retrieves the OAuth access token from the first provider that has one
+func GetAccessToken(opts Options) (string, error) {
+	creds, _, err := ResolveCredentials(Providers(opts))
+	if err != nil {
+		return "", err
+	}
+	return creds.AccessToken, nil
+}
+
+// envProvider reads a bare access token from the environment
+type envProvider struct{}
+
+func (envProvider) Name() string {
+	return "env"
+}
+
+func (envProvider) Credentials() (*Credentials, error) {
+	token := strings.TrimSpace(os.Getenv(TokenEnv))
+	if token == "" {
+		return nil, fmt.Errorf("%s not set", TokenEnv)
+	}
+	return &Credentials{AccessToken: token}, nil
+}
+
+// fileProvider reads Claude Code's credentials file, used where no keyring exists
+type fileProvider struct {
+	path string
+}
+
+func (fileProvider) Name() string {
+	return "credentials-file"
+}
+
+func (f fileProvider) Credentials() (*Credentials, error) {
+	if f.path == "" {
+		return nil, errors.New("credentials file location unknown")
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCredentials(data)
+}
+
+// commandProvider runs a command that prints a token or credentials JSON
+type commandProvider struct {
+	name string
+	args []string
+}
+
+func (c commandProvider) Name() string {
+	return c.name
+}
+
+func (c commandProvider) Credentials() (*Credentials, error) {
+	output, err := exec.Command(c.args[0], c.args[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", c.args[0], err)
+	}
+	return parseCredentials(output)
+}
+
+// credentialsPath returns the location of Claude Code's credentials file,
+// honoring CLAUDE_CONFIG_DIR like Claude Code does
+func credentialsPath() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, ".credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude", ".credentials.json")
+}
+
+// keychainCommand returns the OS keychain lookup command, nil when unsupported
+func keychainCommand() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"security", "find-generic-password", "-s", keychainService, "-w"}
+	case "linux":
+		return []string{"secret-tool", "lookup", "service", keychainService}
+	default:
+		return nil
+	}
+}
+
+// shellCommand wraps a user-supplied command line for the platform shell
+func shellCommand(command string) []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd", "/C", command}
+	}
+	return []string{"sh", "-c", command}
+}
+
+// parseCredentials accepts Claude Code's credentials JSON (nested under
+// claudeAiOauth or flat) or a bare access token
+func parseCredentials(data []byte) (*Credentials, error) {
+	raw := strings.TrimSpace(string(data))
+	if raw == "" {
+		return nil, errors.New("empty credentials")
+	}
+
+	if !strings.HasPrefix(raw, "{") {
+		return &Credentials{AccessToken: raw}, nil
+	}
+
+	type stored struct {
+		AccessToken  string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
+		ExpiresAt    int64  `json:"expiresAt"` // Unix milliseconds
+	}
+	var creds struct {
+		stored
+		ClaudeAiOauth *stored `json:"claudeAiOauth"`
+	}
+	if err := json.Unmarshal([]byte(raw), &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+
+	found := creds.stored
+	if creds.ClaudeAiOauth != nil && creds.ClaudeAiOauth.AccessToken != "" {
+		found = *creds.ClaudeAiOauth
+	}
+	if found.AccessToken == "" {
+		return nil, errors.New("credentials contain no access token")
+	}
+
+	result := &Credentials{AccessToken: found.AccessToken, RefreshToken: found.RefreshToken}
+	if found.ExpiresAt > 0 {
+		result.ExpiresAt = time.UnixMilli(found.ExpiresAt)
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package oauth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// stubProvider returns fixed credentials or an error
+type stubProvider struct {
+	name  string
+	creds *Credentials
+	err   error
+}
+
+func (p stubProvider) Name() string                       { return p.name }
+func (p stubProvider) Credentials() (*Credentials, error) { return p.creds, p.err }
+
+func TestParseCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Credentials
+		wantErr bool
+	}{
+		{
+			name:  "nested claudeAiOauth",
+			input: `{"claudeAiOauth":{"accessToken":"sk-ant-oat01-abc","refreshToken":"sk-ant-ort01-def","expiresAt":1767225600000}}`,
+			want: Credentials{
+				AccessToken:  "sk-ant-oat01-abc",
+				RefreshToken: "sk-ant-ort01-def",
+				ExpiresAt:    time.UnixMilli(1767225600000),
+			},
+		},
+		{
+			name:  "flat",
+			input: `{"accessToken":"flat-token"}`,
+			want:  Credentials{AccessToken: "flat-token"},
+		},
+		{
+			name:  "bare token",
+			input: "  bare-token\n",
+			want:  Credentials{AccessToken: "bare-token"},
+		},
+		{
+			name:    "empty",
+			input:   "\n",
+			wantErr: true,
+		},
+		{
+			name:    "json without token",
+			input:   `{"claudeAiOauth":{}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCredentials([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCredentials() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.AccessToken != tt.want.AccessToken || got.RefreshToken != tt.want.RefreshToken || !got.ExpiresAt.Equal(tt.want.ExpiresAt) {
+				t.Errorf("parseCredentials() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCredentialsOrder(t *testing.T) {
+	providers := []Provider{
+		stubProvider{name: "first", err: os.ErrNotExist},
+		stubProvider{name: "second", creds: &Credentials{AccessToken: "two"}},
+		stubProvider{name: "third", creds: &Credentials{AccessToken: "three"}},
+	}
+
+	creds, name, err := ResolveCredentials(providers)
+	if err != nil {
+		t.Fatalf("ResolveCredentials failed: %v", err)
+	}
+	if name != "second" || creds.AccessToken != "two" {
+		t.Errorf("expected token from 'second', got %q from %q", creds.AccessToken, name)
+	}
+
+	_, _, err = ResolveCredentials([]Provider{stubProvider{name: "only", err: os.ErrNotExist}})
+	if err == nil {
+		t.Error("expected error when no provider has credentials")
+	}
+}
+
+func TestProvidersChain(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", dir)
+	t.Setenv(TokenEnv, "")
+
+	creds := `{"claudeAiOauth":{"accessToken":"from-file"}}`
+	if err := os.WriteFile(filepath.Join(dir, ".credentials.json"), []byte(creds), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, name, err := ResolveCredentials(Providers(Options{}))
+	if err != nil || name != "credentials-file" || got.AccessToken != "from-file" {
+		t.Errorf("expected credentials file, got %+v from %q (%v)", got, name, err)
+	}
+
+	t.Setenv(TokenEnv, "from-env")
+	got, name, err = ResolveCredentials(Providers(Options{}))
+	if err != nil || name != "env" || got.AccessToken != "from-env" {
+		t.Errorf("expected env token to win, got %+v from %q (%v)", got, name, err)
+	}
+}
+
+func TestCommandProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", dir)
+	t.Setenv(TokenEnv, "")
+
+	opts := Options{CredentialCommand: "echo from-command"}
+	got, name, err := ResolveCredentials(Providers(opts))
+	if err != nil || name != "command" || got.AccessToken != "from-command" {
+		t.Errorf("expected custom command, got %+v from %q (%v)", got, name, err)
+	}
+
+	opts.CredentialCommand = "exit 1"
+	providers := Providers(opts)
+	if _, err := providers[1].Credentials(); err == nil {
+		t.Error("expected failing command to return an error")
+	}
+}
@@ -2,89 +2,157 @@ package oauth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os/exec"
-	"runtime"
-	"strings"
+	"strconv"
 	"time"
 )
 
+const (
+	// defaultBaseURL is the Anthropic API
+	defaultBaseURL = "https://api.anthropic.com"
+	// usagePath is the OAuth usage endpoint relative to the base URL
+	usagePath = "/api/oauth/usage"
+)
+
 // UsageResponse represents the API response from oauth/usage endpoint
 type UsageResponse struct {
-	FiveHour struct {
-		Utilization float64   `json:"utilization"`
-		ResetsAt    time.Time `json:"resets_at"`
-	} `json:"five_hour"`
-	SevenDay struct {
-		Utilization float64   `json:"utilization"`
-		ResetsAt    time.Time `json:"resets_at"`
-	} `json:"seven_day"`
+	FiveHour   UsageBucket            `json:"five_hour"`
+	SevenDay   UsageBucket            `json:"seven_day"`
+	Buckets    map[string]UsageBucket `json:"-"` // Every rate limit bucket by name, including five_hour and seven_day
+	ExtraUsage *ExtraUsage            `json:"extra_usage,omitempty"`
 }
 
-// GetAccessToken retrieves the OAuth access token from system keychain
-func GetAccessToken() (string, error) {
-	var cmd *exec.Cmd
-
-	switch runtime.GOOS {
-	case "darwin":
-		// macOS: use security command
-		cmd = exec.Command("security", "find-generic-password", "-s", "Claude Code-credentials", "-w")
-	case "linux":
-		// Linux: use secret-tool
-		cmd = exec.Command("secret-tool", "lookup", "service", "Claude Code-credentials")
-	case "windows":
-		// Windows: not yet supported
-		return "", fmt.Errorf("windows keychain access not yet implemented")
-	default:
-		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
-	}
-
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to retrieve access token: %w", err)
-	}
+// UsageBucket is a single rate limit window
+type UsageBucket struct {
+	Utilization float64   `json:"utilization"`
+	ResetsAt    time.Time `json:"resets_at"`
+}
 
-	token := strings.TrimSpace(string(output))
-	if token == "" {
-		return "", fmt.Errorf("empty access token retrieved")
+// ExtraUsage describes pay-as-you-go usage beyond the plan limits
+type ExtraUsage struct {
+	IsEnabled    bool    `json:"is_enabled"`
+	MonthlyLimit float64 `json:"monthly_limit"`
+	UsedCredits  float64 `json:"used_credits"`
+	Utilization  float64 `json:"utilization"`
+}
+
+// UnmarshalJSON decodes every top-level object with a utilization field as a
+// bucket, so new model-specific limits show up without code changes
+func (u *UsageResponse) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
 	}
 
-	// Parse JSON (security command returns JSON with nested structure)
-	if strings.HasPrefix(token, "{") {
-		// Try nested structure first (claudeAiOauth.accessToken)
-		var nestedCreds struct {
-			ClaudeAiOauth struct {
-				AccessToken  string `json:"accessToken"`
-				RefreshToken string `json:"refreshToken"`
-			} `json:"claudeAiOauth"`
-		}
-		if err := json.Unmarshal([]byte(token), &nestedCreds); err == nil && nestedCreds.ClaudeAiOauth.AccessToken != "" {
-			return nestedCreds.ClaudeAiOauth.AccessToken, nil
+	*u = UsageResponse{Buckets: make(map[string]UsageBucket)}
+
+	for name, value := range raw {
+		if name == "extra_usage" {
+			var extra ExtraUsage
+			if err := json.Unmarshal(value, &extra); err == nil && string(value) != "null" {
+				u.ExtraUsage = &extra
+			}
+			continue
 		}
 
-		// Try flat structure as fallback
-		var creds struct {
-			AccessToken string `json:"accessToken"`
+		var bucket struct {
+			Utilization *float64  `json:"utilization"`
+			ResetsAt    time.Time `json:"resets_at"`
 		}
-		if err := json.Unmarshal([]byte(token), &creds); err == nil && creds.AccessToken != "" {
-			return creds.AccessToken, nil
+		if err := json.Unmarshal(value, &bucket); err != nil || bucket.Utilization == nil {
+			continue
 		}
+		u.Buckets[name] = UsageBucket{Utilization: *bucket.Utilization, ResetsAt: bucket.ResetsAt}
 	}
 
-	return token, nil
+	u.FiveHour = u.Buckets["five_hour"]
+	u.SevenDay = u.Buckets["seven_day"]
+
+	return nil
+}
+
+// MarshalJSON encodes the response in the API's own shape so it round-trips
+// through UnmarshalJSON (used by the on-disk cache)
+func (u UsageResponse) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(u.Buckets)+3)
+	for name, bucket := range u.Buckets {
+		out[name] = bucket
+	}
+	if _, ok := out["five_hour"]; !ok {
+		out["five_hour"] = u.FiveHour
+	}
+	if _, ok := out["seven_day"]; !ok {
+		out["seven_day"] = u.SevenDay
+	}
+	if u.ExtraUsage != nil {
+		out["extra_usage"] = u.ExtraUsage
+	}
+	return json.Marshal(out)
+}
+
+// StatusError is returned when the usage endpoint answers with a non-200 status
+type StatusError struct {
+	Code       int
+	RetryAfter time.Duration // From the Retry-After header, 0 if absent
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.Code, e.Body)
 }
 
 // FetchUsage retrieves rate limit usage from Anthropic OAuth API
-func FetchUsage() (*UsageResponse, error) {
-	token, err := GetAccessToken()
+func FetchUsage(opts Options) (*UsageResponse, error) {
+	if opts.Offline || DetectedOffline(opts.OfflineAfter, time.Now()) {
+		return nil, ErrOffline
+	}
+
+	creds, _, err := ResolveCredentials(Providers(opts))
+	if err != nil {
+		return nil, err
+	}
+	if opts.NoRefresh {
+		// Use the access token as is; the API rejects it once expired
+		withoutRefresh := *creds
+		withoutRefresh.RefreshToken = ""
+		creds = &withoutRefresh
+	}
+
+	client, err := NewClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return fetchUsageRefreshing(client, opts.usageURL(), opts.tokenURL(), creds, time.Now())
+}
+
+// fetchUsageRefreshing fetches usage with creds, refreshing an expired
+// access token first and retrying once if the API rejects the token
+func fetchUsageRefreshing(client *http.Client, usageURL, tokenURL string, creds *Credentials, now time.Time) (*UsageResponse, error) {
+	token, err := accessToken(client, tokenURL, creds, now, false)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := fetchUsage(client, usageURL, token)
+	var statusErr *StatusError
+	if err == nil || !errors.As(err, &statusErr) || statusErr.Code != http.StatusUnauthorized || creds.RefreshToken == "" {
+		return usage, err
+	}
+
+	token, err = accessToken(client, tokenURL, creds, now, true)
 	if err != nil {
 		return nil, err
 	}
+	return fetchUsage(client, usageURL, token)
+}
 
+// fetchUsage requests usage from url with the given bearer token
+func fetchUsage(client *http.Client, url, token string) (*UsageResponse, error) {
 	// Create HTTP request
-	req, err := http.NewRequest("GET", "https://api.anthropic.com/api/oauth/usage", nil)
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -95,9 +163,6 @@ func FetchUsage() (*UsageResponse, error) {
 	req.Header.Set("Accept", "application/json")
 
 	// Execute request
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch usage: %w", err)
@@ -107,7 +172,11 @@ func FetchUsage() (*UsageResponse, error) {
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		statusErr := &StatusError{Code: resp.StatusCode, Body: string(body)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			statusErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, statusErr
 	}
 
 	// Parse response
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 )
 
@@ -13,7 +14,7 @@ func TestGetAccessToken(t *testing.T) {
 		t.Skip("Skipping keychain access test in short mode")
 	}
 
-	token, err := GetAccessToken()
+	token, err := GetAccessToken(Options{})
 	if err != nil {
 		t.Logf("Could not retrieve access token (may not be configured): %v", err)
 		t.Skip("Access token not available")
@@ -87,3 +88,74 @@ func TestUsageResponseParsing(t *testing.T) {
 		t.Error("Expected non-zero reset time for 7d")
 	}
 }
+
+func TestFetchUsageBuckets(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/usage.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(fixture)
+	}))
+	defer server.Close()
+
+	usage, err := fetchUsage(server.Client(), server.URL, "test-token")
+	if err != nil {
+		t.Fatalf("fetchUsage failed: %v", err)
+	}
+
+	if usage.FiveHour.Utilization != 23.5 || usage.SevenDay.Utilization != 67.2 {
+		t.Errorf("expected five_hour/seven_day to be populated, got %+v / %+v", usage.FiveHour, usage.SevenDay)
+	}
+
+	// Null buckets are skipped, everything else is decoded generically
+	wantBuckets := map[string]float64{
+		"five_hour":        23.5,
+		"seven_day":        67.2,
+		"seven_day_opus":   88.0,
+		"seven_day_sonnet": 12.0,
+	}
+	if len(usage.Buckets) != len(wantBuckets) {
+		t.Errorf("expected %d buckets, got %d: %v", len(wantBuckets), len(usage.Buckets), usage.Buckets)
+	}
+	for name, want := range wantBuckets {
+		bucket, ok := usage.Buckets[name]
+		if !ok {
+			t.Errorf("missing bucket %q", name)
+			continue
+		}
+		if bucket.Utilization != want {
+			t.Errorf("bucket %q utilization = %v, want %v", name, bucket.Utilization, want)
+		}
+	}
+
+	if usage.Buckets["seven_day_opus"].ResetsAt.IsZero() {
+		t.Error("expected reset time for seven_day_opus")
+	}
+	if !usage.Buckets["seven_day_sonnet"].ResetsAt.IsZero() {
+		t.Error("expected zero reset time for null resets_at")
+	}
+
+	if usage.ExtraUsage == nil {
+		t.Fatal("expected extra usage to be decoded")
+	}
+	if !usage.ExtraUsage.IsEnabled || usage.ExtraUsage.Utilization != 25.0 {
+		t.Errorf("unexpected extra usage: %+v", usage.ExtraUsage)
+	}
+}
+
+func TestFetchUsageErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if _, err := fetchUsage(server.Client(), server.URL, "bad-token"); err == nil {
+		t.Error("expected error for non-200 response")
+	}
+}
@@ -0,0 +1,154 @@
+package oauth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+const (
+	// defaultTokenURL is the Anthropic OAuth token endpoint
+	defaultTokenURL = "https://console.anthropic.com/v1/oauth/token"
+	// clientID is Claude Code's public OAuth client ID
+	clientID = "9d1c250a-e61b-44d9-88ed-5944d1962f5e"
+	// tokenFile is the store file holding access tokens obtained by refreshing
+	tokenFile = "token.json"
+	// expiryMargin treats tokens as expired slightly early to avoid racing the server
+	expiryMargin = time.Minute
+	// defaultLifetime is assumed when the token response has no expires_in
+	defaultLifetime = time.Hour
+)
+
+// Expired reports whether the access token is known to have expired
+func (c *Credentials) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt.Add(-expiryMargin))
+}
+
+// tokenCache holds a refreshed access token. It lives in cc-hud-go's own
+// store; Claude Code's credentials are never modified.
+type tokenCache struct {
+	Origin       string    `json:"origin"` // Fingerprint of the refresh token it was derived from
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"` // Rotated refresh token, if the server issued one
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// tokenResponse is the token endpoint's answer to a refresh_token grant
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds
+}
+
+// fingerprint identifies a refresh token without storing it in the clear,
+// so a new login invalidates the cached token
+func fingerprint(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+// accessToken returns a usable access token for creds. Expired tokens (or
+// any token when force is set, e.g. after a 401) are exchanged for a new one
+// through tokenURL using the refresh token. Callers only pass a refresh token
+// when the user opted in (Options.RefreshToken).
+func accessToken(client *http.Client, tokenURL string, creds *Credentials, now time.Time, force bool) (string, error) {
+	if !force && !creds.Expired(now) {
+		return creds.AccessToken, nil
+	}
+	if creds.RefreshToken == "" {
+		if force {
+			return "", errors.New("access token rejected and no refresh token available")
+		}
+		// Expiry may be wrong; let the API decide
+		return creds.AccessToken, nil
+	}
+
+	origin := fingerprint(creds.RefreshToken)
+	var cached tokenCache
+	if err := store.Load(tokenFile, &cached); err != nil || cached.Origin != origin {
+		cached = tokenCache{}
+	}
+
+	if !force && cached.AccessToken != "" && now.Before(cached.ExpiresAt.Add(-expiryMargin)) {
+		return cached.AccessToken, nil
+	}
+
+	refreshToken := creds.RefreshToken
+	if cached.RefreshToken != "" {
+		refreshToken = cached.RefreshToken
+	}
+
+	refreshed, err := exchangeRefreshToken(client, tokenURL, refreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep the last refresh token when the server doesn't rotate it
+	if refreshed.RefreshToken != "" {
+		refreshToken = refreshed.RefreshToken
+	}
+	lifetime := defaultLifetime
+	if refreshed.ExpiresIn > 0 {
+		lifetime = time.Duration(refreshed.ExpiresIn) * time.Second
+	}
+
+	cached = tokenCache{
+		Origin:      origin,
+		AccessToken: refreshed.AccessToken,
+		ExpiresAt:   now.Add(lifetime),
+	}
+	if refreshToken != creds.RefreshToken { // Claude Code's own token is only fingerprinted
+		cached.RefreshToken = refreshToken
+	}
+	_ = store.Save(tokenFile, &cached) // An unwritable cache only costs another exchange next time
+
+	return cached.AccessToken, nil
+}
+
+// exchangeRefreshToken performs a refresh_token grant against url
+func exchangeRefreshToken(client *http.Client, url, refreshToken string) (*tokenResponse, error) {
+	body, err := json.Marshal(map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+		"client_id":     clientID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("token refresh failed: %w", &StatusError{Code: resp.StatusCode, Body: string(body)})
+	}
+
+	var refreshed tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&refreshed); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if refreshed.AccessToken == "" {
+		return nil, errors.New("token response contains no access token")
+	}
+
+	return &refreshed, nil
+}
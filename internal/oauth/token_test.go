@@ -0,0 +1,241 @@
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+var tokenBase = time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)
+
+// authServer stands in for the token and usage endpoints. Only tokens in
+// valid are accepted by the usage endpoint; refreshing issues "fresh-N".
+type authServer struct {
+	*httptest.Server
+	valid     map[string]bool
+	exchanges int
+	lastGrant map[string]string
+	minimal   bool // Answer with only an access token: no rotation, no expires_in
+}
+
+func newAuthServer(t *testing.T, valid ...string) *authServer {
+	t.Helper()
+	fixture, err := os.ReadFile("testdata/usage.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &authServer{valid: map[string]bool{}}
+	for _, token := range valid {
+		s.valid[token] = true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST to token endpoint, got %s", r.Method)
+		}
+		s.lastGrant = map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&s.lastGrant)
+		if s.lastGrant["refresh_token"] == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		s.exchanges++
+		token := "fresh-" + string(rune('0'+s.exchanges))
+		s.valid[token] = true
+		if s.minimal {
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": token})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  token,
+			"refresh_token": "rotated-" + string(rune('0'+s.exchanges)),
+			"expires_in":    3600,
+		})
+	})
+	mux.HandleFunc("/api/oauth/usage", func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")[len("Bearer "):]
+		if !s.valid[token] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(fixture)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *authServer) fetch(creds *Credentials, now time.Time) (*UsageResponse, error) {
+	return fetchUsageRefreshing(s.Client(), s.URL+usagePath, s.URL+"/v1/oauth/token", creds, now)
+}
+
+func TestFetchUsageRefreshesExpiredToken(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	server := newAuthServer(t)
+
+	creds := &Credentials{AccessToken: "expired", RefreshToken: "refresh-1", ExpiresAt: tokenBase.Add(-time.Hour)}
+
+	usage, err := server.fetch(creds, tokenBase)
+	if err != nil {
+		t.Fatalf("expected fetch to succeed after refresh: %v", err)
+	}
+	if usage.FiveHour.Utilization == 0 {
+		t.Error("expected usage from fixture")
+	}
+	if server.exchanges != 1 {
+		t.Errorf("expected 1 token exchange, got %d", server.exchanges)
+	}
+	if server.lastGrant["grant_type"] != "refresh_token" || server.lastGrant["refresh_token"] != "refresh-1" || server.lastGrant["client_id"] != clientID {
+		t.Errorf("unexpected grant: %v", server.lastGrant)
+	}
+
+	// The refreshed token is reused from the store instead of exchanging again
+	if _, err := server.fetch(creds, tokenBase.Add(10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if server.exchanges != 1 {
+		t.Errorf("expected cached token to be reused, got %d exchanges", server.exchanges)
+	}
+
+	// Once the refreshed token expires too, the rotated refresh token is used
+	if _, err := server.fetch(creds, tokenBase.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if server.exchanges != 2 || server.lastGrant["refresh_token"] != "rotated-1" {
+		t.Errorf("expected exchange with rotated token, got %d exchanges, grant %v", server.exchanges, server.lastGrant)
+	}
+}
+
+func TestFetchUsageRefreshesOnUnauthorized(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	server := newAuthServer(t)
+
+	// No expiry known, but the server rejects the token
+	creds := &Credentials{AccessToken: "revoked-access", RefreshToken: "refresh-1"}
+
+	if _, err := server.fetch(creds, tokenBase); err != nil {
+		t.Fatalf("expected retry with refreshed token to succeed: %v", err)
+	}
+	if server.exchanges != 1 {
+		t.Errorf("expected 1 token exchange, got %d", server.exchanges)
+	}
+}
+
+func TestFetchUsageValidTokenSkipsRefresh(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	server := newAuthServer(t, "good")
+
+	creds := &Credentials{AccessToken: "good", RefreshToken: "refresh-1", ExpiresAt: tokenBase.Add(time.Hour)}
+	if _, err := server.fetch(creds, tokenBase); err != nil {
+		t.Fatal(err)
+	}
+	if server.exchanges != 0 {
+		t.Errorf("expected no token exchange, got %d", server.exchanges)
+	}
+}
+
+func TestFetchUsageRefreshFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(store.DirEnv, dir)
+	server := newAuthServer(t)
+
+	creds := &Credentials{AccessToken: "expired", RefreshToken: "revoked", ExpiresAt: tokenBase.Add(-time.Hour)}
+	if _, err := server.fetch(creds, tokenBase); err == nil {
+		t.Error("expected error when refresh token is rejected")
+	}
+
+	// Without a refresh token a 401 is returned as is
+	creds = &Credentials{AccessToken: "unknown"}
+	if _, err := server.fetch(creds, tokenBase); err == nil {
+		t.Error("expected 401 error")
+	}
+}
+
+func TestFetchUsageKeepsRotatedTokenWithoutReplacement(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	server := newAuthServer(t)
+
+	creds := &Credentials{AccessToken: "expired", RefreshToken: "refresh-1", ExpiresAt: tokenBase.Add(-time.Hour)}
+	if _, err := server.fetch(creds, tokenBase); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next answer carries neither a new refresh token nor expires_in
+	server.minimal = true
+	if _, err := server.fetch(creds, tokenBase.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if server.lastGrant["refresh_token"] != "rotated-1" {
+		t.Errorf("expected exchange with rotated token, got grant %v", server.lastGrant)
+	}
+
+	var cached tokenCache
+	if err := store.Load(tokenFile, &cached); err != nil {
+		t.Fatal(err)
+	}
+	if cached.RefreshToken != "rotated-1" {
+		t.Errorf("expected rotated refresh token to be kept, got %q", cached.RefreshToken)
+	}
+
+	// A missing expires_in falls back to the default lifetime instead of
+	// exchanging again on every fetch
+	if _, err := server.fetch(creds, tokenBase.Add(2*time.Hour+10*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if server.exchanges != 2 {
+		t.Errorf("expected the token to be reused, got %d exchanges", server.exchanges)
+	}
+}
+
+func TestFetchUsageRefreshCanBeDisabled(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	t.Setenv(TokenEnv, "")
+	server := newAuthServer(t)
+
+	expiresAt := time.Now().Add(-time.Hour).UnixMilli()
+	opts := Options{
+		CredentialCommand: fmt.Sprintf(`echo '{"accessToken":"expired","refreshToken":"refresh-1","expiresAt":%d}'`, expiresAt),
+		BaseURL:           server.URL,
+		TokenURL:          server.URL + "/v1/oauth/token",
+		NoRefresh:         true,
+	}
+
+	if _, err := FetchUsage(opts); err == nil {
+		t.Error("expected the expired token to be rejected")
+	}
+	if server.exchanges != 0 {
+		t.Errorf("expected Claude Code's refresh token to be left alone, got %d exchanges", server.exchanges)
+	}
+
+	// By default the expired token is refreshed
+	opts.NoRefresh = false
+	if _, err := FetchUsage(opts); err != nil {
+		t.Fatalf("expected fetch to succeed with refresh enabled: %v", err)
+	}
+	if server.exchanges != 1 {
+		t.Errorf("expected 1 token exchange, got %d", server.exchanges)
+	}
+}
+
+func TestCredentialsExpired(t *testing.T) {
+	creds := &Credentials{ExpiresAt: tokenBase}
+	if creds.Expired(tokenBase.Add(-2 * expiryMargin)) {
+		t.Error("expected token to be valid well before expiry")
+	}
+	if !creds.Expired(tokenBase.Add(-expiryMargin / 2)) {
+		t.Error("expected token to count as expired within the margin")
+	}
+	if (&Credentials{}).Expired(tokenBase) {
+		t.Error("expected unknown expiry never to count as expired")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package refresh
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs the command in its own session so it outlives the statusline process
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
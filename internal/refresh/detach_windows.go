@@ -0,0 +1,18 @@
+//go:build windows
+
+package refresh
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess is the Windows DETACHED_PROCESS creation flag
+const detachedProcess = 0x00000008
+
+// detach runs the command without a console so it outlives the statusline process
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
@@ -0,0 +1,68 @@
+package refresh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+// lockFile guards the OAuth fetch, which every session shares, so concurrent
+// statusline invocations don't stampede the API
+const lockFile = "refresh.lock"
+
+// lockTTL is how long a lock is honored before it is assumed abandoned
+// (e.g. the refresh process was killed)
+const lockTTL = 30 * time.Second
+
+// repoLockFile guards the git refresh of one workspace, so a slow repository
+// doesn't hold up the others
+func repoLockFile(dir string) string {
+	sum := sha256.Sum256([]byte(dir))
+	return "refresh-" + hex.EncodeToString(sum[:8]) + ".lock"
+}
+
+// Locked reports whether a background refresh currently holds the OAuth lock
+func Locked(now time.Time) bool {
+	return locked(lockFile, now)
+}
+
+// RepoLocked reports whether a background refresh currently holds the lock
+// for the workspace in dir
+func RepoLocked(dir string, now time.Time) bool {
+	return locked(repoLockFile(dir), now)
+}
+
+func locked(name string, now time.Time) bool {
+	path, err := store.Path(name)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return now.Sub(info.ModTime()) < lockTTL
+}
+
+// Acquire takes the OAuth refresh lock, breaking it if it has gone stale.
+// The returned release func removes the lock.
+func Acquire(now time.Time) (func(), error) {
+	return acquire(lockFile, now)
+}
+
+// AcquireRepo takes the refresh lock for the workspace in dir
+func AcquireRepo(dir string, now time.Time) (func(), error) {
+	return acquire(repoLockFile(dir), now)
+}
+
+func acquire(name string, now time.Time) (func(), error) {
+	release, err := store.TryLock(name, lockTTL, now)
+	if errors.Is(err, store.ErrLocked) {
+		return nil, errors.New("refresh already in progress")
+	}
+	return release, err
+}
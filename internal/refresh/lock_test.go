@@ -0,0 +1,97 @@
+package refresh
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/store"
+)
+
+func TestAcquireIsExclusive(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	now := time.Now()
+
+	if Locked(now) {
+		t.Fatal("expected no lock initially")
+	}
+
+	release, err := Acquire(now)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	if !Locked(now) {
+		t.Error("expected lock to be held")
+	}
+
+	if _, err := Acquire(now); err == nil {
+		t.Error("expected second Acquire to fail while locked")
+	}
+
+	release()
+
+	if Locked(now) {
+		t.Error("expected lock to be released")
+	}
+
+	release, err = Acquire(now)
+	if err != nil {
+		t.Fatalf("expected Acquire to succeed after release: %v", err)
+	}
+	release()
+}
+
+func TestAcquireBreaksStaleLock(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+
+	release, err := Acquire(time.Now())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer release()
+
+	// Simulate a refresh process that died long ago
+	path, _ := store.Path(lockFile)
+	old := time.Now().Add(-2 * lockTTL)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if Locked(time.Now()) {
+		t.Error("expected stale lock to be ignored")
+	}
+
+	release2, err := Acquire(time.Now())
+	if err != nil {
+		t.Fatalf("expected stale lock to be broken: %v", err)
+	}
+	release2()
+}
+
+func TestRepoLocksAreIndependent(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	now := time.Now()
+
+	release, err := AcquireRepo("/work/a", now)
+	if err != nil {
+		t.Fatalf("AcquireRepo failed: %v", err)
+	}
+	defer release()
+
+	if !RepoLocked("/work/a", now) || RepoLocked("/work/b", now) {
+		t.Error("expected only the first workspace to be locked")
+	}
+	if Locked(now) {
+		t.Error("expected the OAuth lock to stay free")
+	}
+	if _, err := AcquireRepo("/work/a", now); err == nil {
+		t.Error("expected a second refresh of the same workspace to fail")
+	}
+
+	other, err := AcquireRepo("/work/b", now)
+	if err != nil {
+		t.Fatalf("expected another workspace to refresh concurrently: %v", err)
+	}
+	other()
+}
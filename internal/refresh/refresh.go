@@ -0,0 +1,85 @@
+package refresh
+
+import (
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/internal/git"
+	"github.com/huyhandes/cc-hud-go/internal/oauth"
+)
+
+// Options selects which sources a background refresh updates
+type Options struct {
+	Dir         string        // Working directory whose git status is cached
+	SessionID   string        // Session whose commits in Dir are recorded; empty skips them
+	Git         bool          // Refresh git status
+	GitBackend  string        // Git provider backend ("exec" or "native")
+	GitOptions  git.Options   // Optional git information to collect
+	ExtraRepos  []string      // More repositories whose git status is cached
+	RepoTimeout time.Duration // Time allowed per extra repository
+	OAuth       bool          // Refresh OAuth usage
+	OAuthTTL    time.Duration // Reuse OAuth usage younger than this
+	Fetch       oauth.Options // How OAuth usage is fetched
+}
+
+// Run refreshes the selected caches. Git status is refreshed under the lock
+// of its workspace and OAuth usage under the shared lock, so workspaces don't
+// wait on each other; a source whose lock is held is skipped. It returns an
+// error if nothing could be refreshed for that reason.
+func Run(opts Options, now time.Time) error {
+	var busy error
+	ran := false
+
+	if opts.Git && opts.Dir != "" {
+		if release, err := AcquireRepo(opts.Dir, now); err == nil {
+			refreshGit(opts, now)
+			release()
+			ran = true
+		} else {
+			busy = err
+		}
+	}
+
+	if opts.OAuth {
+		if release, err := Acquire(now); err == nil {
+			// Failures are recorded in the cache and backed off
+			_, _ = oauth.FetchUsageCached(opts.OAuthTTL, func() (*oauth.UsageResponse, error) {
+				return oauth.FetchUsage(opts.Fetch)
+			}, now)
+			release()
+			ran = true
+		} else {
+			busy = err
+		}
+	}
+
+	if !ran {
+		return busy
+	}
+	return nil
+}
+
+// refreshGit refreshes the workspace's git status and session commits, and
+// the extra repositories
+func refreshGit(opts Options, now time.Time) {
+	// Failures leave an empty entry so a broken repo isn't retried on every render
+	entries, _ := git.RefreshCache(gitProvider(opts.GitBackend, opts.GitOptions), []string{opts.Dir}, 0, now)
+
+	// Session commits start from the HEAD just collected
+	if entry := entries[opts.Dir]; opts.SessionID != "" && entry != nil && entry.Status != nil {
+		_, _ = git.SessionCommits(gitProvider(opts.GitBackend, git.Options{}), opts.SessionID, opts.Dir, entry.Status.HeadOID, now)
+	}
+
+	if len(opts.ExtraRepos) > 0 {
+		// Extra repos only show branch and dirty count
+		_, _ = git.RefreshCache(gitProvider(opts.GitBackend, git.Options{}), opts.ExtraRepos, opts.RepoTimeout, now)
+	}
+}
+
+// gitProvider returns the named git backend, falling back to the git CLI
+func gitProvider(backend string, opts git.Options) git.Provider {
+	provider, err := git.NewProvider(backend, opts)
+	if err != nil {
+		return git.ExecProvider{Options: opts}
+	}
+	return provider
+}
@@ -0,0 +1,43 @@
+package refresh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+const (
+	// Flag is the hidden command-line flag that runs a background refresh
+	Flag = "--refresh"
+	// DirFlag passes the repository directory to refresh to the background process
+	DirFlag = "--refresh-dir"
+	// SessionFlag passes the session whose commits are recorded in that repository
+	SessionFlag = "--refresh-session"
+)
+
+// Spawn starts a detached copy of the current executable that refreshes the
+// caches for the repository in dir, and the commits of session sessionID in
+// it when not empty. It returns immediately.
+func Spawn(dir, sessionID string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	args := []string{Flag, DirFlag + "=" + dir}
+	if sessionID != "" {
+		args = append(args, SessionFlag+"="+sessionID)
+	}
+	cmd := exec.Command(exe, args...)
+	// No stdio: Claude Code waits for stdout to close before reading the statusline
+	cmd.Stdin = nil
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start refresh: %w", err)
+	}
+
+	return cmd.Process.Release()
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned when another process holds a lock
+var ErrLocked = errors.New("lock is held by another process")
+
+// lockRetry is how often Lock tries again while a lock is held
+const lockRetry = 10 * time.Millisecond
+
+// TryLock creates the lock file name exclusively, first breaking it if it is
+// older than ttl (its holder was killed). The returned release func removes it.
+func TryLock(name string, ttl time.Duration, now time.Time) (func(), error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil && now.Sub(info.ModTime()) >= ttl {
+		_ = os.Remove(path)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("failed to create lock: %w", err)
+	}
+	_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+	_ = f.Close()
+
+	return func() { _ = os.Remove(path) }, nil
+}
+
+// Lock is TryLock waiting up to wait for the lock to be released. It guards
+// short load/modify/save cycles on files shared by concurrent sessions.
+func Lock(name string, ttl, wait time.Duration) (func(), error) {
+	deadline := time.Now().Add(wait)
+	for {
+		release, err := TryLock(name, ttl, time.Now())
+		if !errors.Is(err, ErrLocked) || !time.Now().Before(deadline) {
+			return release, err
+		}
+		time.Sleep(lockRetry)
+	}
+}
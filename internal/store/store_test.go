@@ -0,0 +1,98 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirOverride(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+
+	got, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir() failed: %v", err)
+	}
+	if got != dir {
+		t.Errorf("Dir() = %q, want %q", got, dir)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	t.Setenv(DirEnv, dir)
+
+	type record struct {
+		Name  string
+		Count int
+	}
+
+	if err := Save("record.json", record{Name: "abc", Count: 3}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var got record
+	if err := Load("record.json", &got); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got.Name != "abc" || got.Count != 3 {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+
+	// No temp files should be left behind
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected exactly one file in data dir, got %d", len(entries))
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Setenv(DirEnv, t.TempDir())
+
+	got := map[string]int{"keep": 1}
+	if err := Load("missing.json", &got); err != nil {
+		t.Fatalf("missing file should not error: %v", err)
+	}
+	if got["keep"] != 1 {
+		t.Error("missing file should leave value untouched")
+	}
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var v map[string]int
+	if err := Load("bad.json", &v); err == nil {
+		t.Error("expected error for corrupt file")
+	}
+}
+
+func TestLockWaitsForRelease(t *testing.T) {
+	t.Setenv(DirEnv, t.TempDir())
+
+	release, err := TryLock("test.lock", time.Minute, time.Now())
+	if err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+	if _, err := TryLock("test.lock", time.Minute, time.Now()); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while held, got %v", err)
+	}
+	if _, err := Lock("test.lock", time.Minute, 20*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected Lock to give up with ErrLocked, got %v", err)
+	}
+
+	time.AfterFunc(20*time.Millisecond, release)
+	release, err = Lock("test.lock", time.Minute, 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected Lock to succeed once released: %v", err)
+	}
+	release()
+}
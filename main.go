@@ -6,10 +6,16 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
+	"github.com/huyhandes/cc-hud-go/internal/budget"
+	"github.com/huyhandes/cc-hud-go/internal/burndown"
 	"github.com/huyhandes/cc-hud-go/internal/git"
 	"github.com/huyhandes/cc-hud-go/internal/oauth"
+	"github.com/huyhandes/cc-hud-go/internal/refresh"
 	"github.com/huyhandes/cc-hud-go/output"
 	"github.com/huyhandes/cc-hud-go/parser"
 	"github.com/huyhandes/cc-hud-go/state"
@@ -23,6 +29,8 @@ func printUsage() {
 
 USAGE:
     cc-hud-go [OPTIONS]
+    cc-hud-go debug auth
+    cc-hud-go session commits <id>
 
 DESCRIPTION:
     A Go-based statusline tool for Claude Code that displays rich, real-time
@@ -34,6 +42,16 @@ DESCRIPTION:
 OPTIONS:
     -h, --help     Show this help message and exit
     -v, --version  Print version information and exit
+    --color=MODE   Colorize output: auto (default), always or never.
+                   auto honors NO_COLOR and detects 16, 256 or true color
+                   support from TERM and COLORTERM
+    --format=FMT   Output format: text (the statusline), json (the session
+                   state and each segment as plain text) or env (KEY=value
+                   lines a shell can source). Overrides outputFormat
+
+COMMANDS:
+    debug auth               Show which OAuth credential source is used
+    session commits <id>     List the commits a session created
 
 CONFIGURATION:
     Config file: ~/.claude/cc-hud-go/config.json
@@ -59,9 +77,18 @@ EXAMPLES:
     # Test with sample data
     echo '{"model":"claude-sonnet-4.5"}' | cc-hud-go
 
+    # Inspect the session state as JSON
+    echo '{"model":"claude-sonnet-4.5"}' | cc-hud-go --format=json
+
     # Check version
     cc-hud-go --version
 
+    # Check where OAuth credentials come from
+    cc-hud-go debug auth
+
+    # List what a session committed (the ID is in the transcript file name)
+    cc-hud-go session commits 3f2a9c1e-8b7d-4a5f-9e3d-2c1b0a9f8e7d
+
     # Show help
     cc-hud-go --help
 
@@ -80,12 +107,23 @@ func main() {
 	var (
 		versionFlag bool
 		helpFlag    bool
+		refreshFlag bool
+		refreshDir  string
+		refreshID   string
+		colorFlag   string
+		formatFlag  string
 	)
 
 	flag.BoolVar(&versionFlag, "version", false, "Print version and exit")
 	flag.BoolVar(&versionFlag, "v", false, "Print version and exit (shorthand)")
 	flag.BoolVar(&helpFlag, "help", false, "Show help message and exit")
 	flag.BoolVar(&helpFlag, "h", false, "Show help message and exit (shorthand)")
+	flag.StringVar(&colorFlag, "color", style.ColorAuto, "Colorize output: auto, always or never")
+	flag.StringVar(&formatFlag, "format", "", "Output format: text, json or env")
+	// Internal: run by the statusline itself to refresh caches in the background
+	flag.BoolVar(&refreshFlag, "refresh", false, "Refresh cached data and exit")
+	flag.StringVar(&refreshDir, "refresh-dir", "", "Repository directory to refresh")
+	flag.StringVar(&refreshID, "refresh-session", "", "Session whose commits to record")
 
 	// Parse flags
 	flag.Parse()
@@ -101,6 +139,20 @@ func main() {
 		fmt.Println(version.Get())
 		os.Exit(0)
 	}
+
+	switch colorFlag {
+	case style.ColorAuto, style.ColorAlways, style.ColorNever:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid --color %q: use auto, always or never\n", colorFlag)
+		os.Exit(2)
+	}
+	switch formatFlag {
+	case "", output.FormatText, output.FormatJSON, output.FormatEnv:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid --format %q: use text, json or env\n", formatFlag)
+		os.Exit(2)
+	}
+
 	// Load config
 	home, _ := os.UserHomeDir()
 	configPath := filepath.Join(home, ".claude", "cc-hud-go", "config.json")
@@ -108,10 +160,37 @@ func main() {
 	if err != nil {
 		cfg = config.Default()
 	}
+	if formatFlag != "" {
+		cfg.OutputFormat = formatFlag
+	}
+
+	// Subcommands (e.g. "debug auth")
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args(), cfg, os.Stdout))
+	}
+
+	// Background refresh spawned by a previous invocation
+	if refreshFlag {
+		_ = refresh.Run(refresh.Options{
+			Dir:         refreshDir,
+			SessionID:   sessionToRecord(cfg, refreshID),
+			Git:         cfg.Display.Git,
+			GitBackend:  cfg.Git.Backend,
+			GitOptions:  gitOptions(cfg),
+			ExtraRepos:  extraRepos(cfg, refreshDir),
+			RepoTimeout: repoTimeout(cfg),
+			OAuth:       cfg.Display.FetchOAuth && !oauthOffline(cfg, time.Now()),
+			OAuthTTL:    time.Duration(cfg.OAuth.CacheTTL) * time.Second,
+			Fetch:       oauthOptions(cfg),
+		}, time.Now())
+		return
+	}
 
 	// Initialize theme and style system
 	themeInstance := theme.LoadThemeFromConfig(cfg.Theme, cfg.Colors)
 	style.Init(themeInstance)
+	style.SetColorProfile(style.DetectProfile(colorFlag, cfg.ColorProfile, os.Getenv))
+	icons.Init(cfg.IconSet, cfg.Icons)
 
 	// Initialize state
 	s := state.New()
@@ -137,29 +216,27 @@ func main() {
 		}
 	}
 
-	// Update git information
-	if branch, err := git.GetBranch(); err == nil {
-		s.Git.Branch = branch
+	// Record this session's spend and aggregate across sessions (if budgets are set)
+	if cfg.Budget.Enabled() && s.Session.ID != "" {
+		totals, err := budget.Update(s.Session.ID, s.Cost.TotalUSD, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update budget ledger: %v\n", err)
+		}
+		s.Budget.DailyUSD = totals.Daily
+		s.Budget.WeeklyUSD = totals.Weekly
+		s.Budget.MonthlyUSD = totals.Monthly
 	}
 
-	if status, err := git.GetStatus(); err == nil {
-		s.Git.DirtyFiles = status.DirtyFiles
-		s.Git.Ahead = status.Ahead
-		s.Git.Behind = status.Behind
-		s.Git.Added = status.Added
-		s.Git.Modified = status.Modified
-		s.Git.Deleted = status.Deleted
+	// Update git and OAuth usage, from cache when refreshing in the background
+	if cfg.Refresh.Async {
+		loadCached(s, cfg, time.Now())
+	} else {
+		fetchSync(s, cfg)
 	}
 
-	// Fetch rate limit usage from OAuth API (if enabled)
-	if cfg.Display.FetchOAuth {
-		if usage, err := oauth.FetchUsage(); err == nil {
-			s.RateLimits.FiveHourPercent = usage.FiveHour.Utilization
-			s.RateLimits.SevenDayPercent = usage.SevenDay.Utilization
-			s.RateLimits.FiveHourResetsAt = usage.FiveHour.ResetsAt.Format("2006-01-02T15:04:05Z07:00")
-			s.RateLimits.SevenDayResetsAt = usage.SevenDay.ResetsAt.Format("2006-01-02T15:04:05Z07:00")
-		}
-		// Silently fail if OAuth fetch fails - we'll use stdin data as fallback
+	// Fit lines to the terminal unless a width is configured
+	if cfg.Width == 0 {
+		cfg.Width = output.TerminalWidth()
 	}
 
 	// Render and output statusline
@@ -172,3 +249,306 @@ func main() {
 	// Output to stdout and exit
 	fmt.Println(result)
 }
+
+// fetchSync collects git status and OAuth usage before rendering
+func fetchSync(s *state.State, cfg *config.Config) {
+	dir := repoDir(s)
+	provider := gitProvider(cfg.Git.Backend, gitOptions(cfg))
+	branch, _ := provider.Branch(dir)
+	status, _ := provider.Status(dir)
+	applyGit(s, branch, status)
+	applySessionCommits(s, cfg, dir, status, time.Now())
+
+	// Extra repos only show branch and dirty count
+	if extras := extraRepos(cfg, dir); len(extras) > 0 {
+		entries := git.Collect(gitProvider(cfg.Git.Backend, git.Options{}), extras, repoTimeout(cfg), time.Now())
+		applyRepos(s, extras, entries)
+	}
+
+	// Fetch rate limit usage from OAuth API (if enabled), reusing the on-disk cache
+	if cfg.Display.FetchOAuth {
+		ttl := time.Duration(cfg.OAuth.CacheTTL) * time.Second
+		fetch := func() (*oauth.UsageResponse, error) { return oauth.FetchUsage(oauthOptions(cfg)) }
+		if cached, err := oauth.FetchUsageCached(ttl, fetch, time.Now()); err == nil {
+			applyUsage(s, cfg, cached)
+		}
+		// Silently fail if OAuth fetch fails - we'll use stdin data as fallback
+	}
+}
+
+// gitProvider returns the named git backend, falling back to the git CLI
+func gitProvider(backend string, opts git.Options) git.Provider {
+	provider, err := git.NewProvider(backend, opts)
+	if err != nil {
+		return git.ExecProvider{Options: opts}
+	}
+	return provider
+}
+
+// gitOptions maps git config to the optional information providers collect
+func gitOptions(cfg *config.Config) git.Options {
+	return git.Options{
+		CompareBase: cfg.Git.ShowBase,
+		BaseBranch:  cfg.Git.BaseBranch,
+		Stash:       cfg.Git.ShowStash,
+		Tag:         cfg.Git.ShowTag,
+		LineStats:   cfg.Git.ShowLineStats,
+		LastCommit:  cfg.Git.ShowLastCommit,
+		DirtyAge:    cfg.Git.DirtyWarnMinutes > 0,
+	}
+}
+
+// loadCached fills git status and OAuth usage from the caches without
+// blocking, and spawns a background refresh when either is out of date
+func loadCached(s *state.State, cfg *config.Config, now time.Time) {
+	gitDue, oauthDue := false, false
+	dir := repoDir(s)
+
+	if cfg.Display.Git && dir != "" {
+		entry, ok := git.LoadCache(dir)
+		if ok {
+			applyGit(s, entry.Branch, entry.Status)
+			gitDue = !loadSessionCommits(s, cfg, dir, entry.Status)
+		}
+		gitTTL := time.Duration(cfg.Refresh.GitTTL) * time.Second
+		gitDue = gitDue || !ok || !entry.Fresh(gitTTL, now)
+
+		extras := extraRepos(cfg, dir)
+		entries := make(map[string]*git.CacheEntry, len(extras))
+		for _, extra := range extras {
+			entry, ok := git.LoadCache(extra)
+			if ok {
+				entries[extra] = entry
+			}
+			gitDue = gitDue || !ok || !entry.Fresh(gitTTL, now)
+		}
+		applyRepos(s, extras, entries)
+	}
+
+	if cfg.Display.FetchOAuth {
+		ttl := time.Duration(cfg.OAuth.CacheTTL) * time.Second
+		cached, due := oauth.PeekUsage(ttl, now)
+		if cached != nil {
+			applyUsage(s, cfg, cached)
+		}
+		oauthDue = due && !oauthOffline(cfg, now)
+	}
+
+	// Each workspace refreshes git under its own lock; OAuth is shared
+	if (gitDue && !refresh.RepoLocked(dir, now)) || (oauthDue && !refresh.Locked(now)) {
+		if err := refresh.Spawn(dir, sessionToRecord(cfg, s.Session.ID)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to start background refresh: %v\n", err)
+		}
+	}
+}
+
+// oauthOptions builds OAuth fetch options from the config
+func oauthOptions(cfg *config.Config) oauth.Options {
+	return oauth.Options{
+		CredentialCommand: cfg.OAuth.CredentialCommand,
+		BaseURL:           cfg.OAuth.BaseURL,
+		ProxyURL:          cfg.OAuth.ProxyURL,
+		CAFile:            cfg.OAuth.CAFile,
+		Timeout:           time.Duration(cfg.OAuth.Timeout) * time.Second,
+		Headers:           cfg.OAuth.Headers,
+		Offline:           offlineForced(cfg),
+		OfflineAfter:      cfg.OAuth.OfflineAfter,
+		NoRefresh:         !cfg.OAuth.RefreshToken,
+	}
+}
+
+// oauthOffline reports whether offline mode is forced by config or
+// environment, or was detected after repeated network failures
+func oauthOffline(cfg *config.Config, now time.Time) bool {
+	return offlineForced(cfg) || oauth.DetectedOffline(cfg.OAuth.OfflineAfter, now)
+}
+
+// offlineForced reports whether offline mode is set by config or environment
+func offlineForced(cfg *config.Config) bool {
+	return cfg.OAuth.Offline || oauth.OfflineFromEnv()
+}
+
+// repoDir returns the directory whose repository is shown: where Claude is
+// working, falling back to the process working directory
+func repoDir(s *state.State) string {
+	if s.Session.WorkDir != "" {
+		return s.Session.WorkDir
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// extraRepos resolves the configured extra repositories: "~" is the home
+// directory and relative paths are relative to the workspace directory
+func extraRepos(cfg *config.Config, workDir string) []string {
+	home, _ := os.UserHomeDir()
+	seen := map[string]bool{filepath.Clean(workDir): true}
+
+	var dirs []string
+	for _, path := range cfg.Git.ExtraRepos {
+		switch {
+		case path == "":
+			continue
+		case path == "~" || strings.HasPrefix(path, "~/"):
+			path = filepath.Join(home, path[1:])
+		case !filepath.IsAbs(path):
+			path = filepath.Join(workDir, path)
+		}
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			dirs = append(dirs, path)
+		}
+	}
+	return dirs
+}
+
+// repoTimeout is how long each extra repository may take
+func repoTimeout(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.Git.RepoTimeout) * time.Millisecond
+}
+
+// applyRepos copies extra repositories into state in configured order,
+// skipping directories that aren't repositories
+func applyRepos(s *state.State, dirs []string, entries map[string]*git.CacheEntry) {
+	s.Git.Repos = nil
+	for _, dir := range dirs {
+		entry := entries[dir]
+		if entry == nil || (entry.Branch == "" && !entry.TimedOut) {
+			continue
+		}
+		repo := state.RepoInfo{
+			Name:     filepath.Base(dir),
+			Branch:   entry.Branch,
+			TimedOut: entry.TimedOut,
+		}
+		if entry.Status != nil {
+			repo.DirtyFiles = entry.Status.DirtyFiles
+		}
+		s.Git.Repos = append(s.Git.Repos, repo)
+	}
+}
+
+// applySessionCommits records where HEAD was when the session started and
+// copies the commits created since into state. Listing them only runs git
+// when HEAD has moved.
+func applySessionCommits(s *state.State, cfg *config.Config, dir string, status *git.Status, now time.Time) {
+	if !cfg.Git.ShowSessionCommits || s.Session.ID == "" || status == nil {
+		return
+	}
+
+	provider := gitProvider(cfg.Git.Backend, git.Options{})
+	commits, err := git.SessionCommits(provider, s.Session.ID, dir, status.HeadOID, now)
+	if err != nil {
+		return
+	}
+	setSessionCommits(s, commits)
+}
+
+// loadSessionCommits copies the commits recorded in the session ledger into
+// state without running git. It reports false when the ledger doesn't cover
+// HEAD yet, so a background refresh is due.
+func loadSessionCommits(s *state.State, cfg *config.Config, dir string, status *git.Status) bool {
+	if sessionToRecord(cfg, s.Session.ID) == "" || status == nil {
+		return true
+	}
+	start := git.LoadSession(s.Session.ID)[dir]
+	if start == nil {
+		return false
+	}
+	setSessionCommits(s, start.Commits)
+	return start.LastHead == status.HeadOID
+}
+
+// sessionToRecord returns the session whose commits are tracked, or "" when
+// session commits are hidden
+func sessionToRecord(cfg *config.Config, sessionID string) string {
+	if !cfg.Git.ShowSessionCommits {
+		return ""
+	}
+	return sessionID
+}
+
+// setSessionCommits copies session commits into state
+func setSessionCommits(s *state.State, commits []git.Commit) {
+	s.Git.SessionCommits = nil
+	for _, commit := range commits {
+		s.Git.SessionCommits = append(s.Git.SessionCommits, state.SessionCommit{
+			ID:      commit.ID,
+			Subject: commit.Subject,
+			Lines:   state.LineStats(commit.Lines),
+		})
+	}
+}
+
+// applyGit copies git branch and status into state
+func applyGit(s *state.State, branch string, status *git.Status) {
+	s.Git.Branch = branch
+	if status == nil {
+		return
+	}
+	s.Git.DirtyFiles = status.DirtyFiles
+	s.Git.Ahead = status.Ahead
+	s.Git.Behind = status.Behind
+	s.Git.Added = status.Added
+	s.Git.Modified = status.Modified
+	s.Git.Deleted = status.Deleted
+	s.Git.Renamed = status.Renamed
+	s.Git.Untracked = status.Untracked
+	s.Git.Conflicts = status.Conflicts
+	s.Git.Stashes = status.Stashes
+	s.Git.Detached = status.Detached
+	s.Git.Head = status.Head
+	s.Git.Operation = status.Operation
+	s.Git.Tag = status.Tag
+	s.Git.Unstaged = state.LineStats(status.Unstaged)
+	s.Git.Staged = state.LineStats(status.Staged)
+	s.Git.Total = state.LineStats(status.Total)
+	s.Git.Base = status.Base
+	s.Git.BaseCommits = status.BaseCommits
+	s.Git.BaseLines = state.LineStats(status.BaseLines)
+	s.Git.Worktree = status.Worktree
+	if committed := status.LastCommitTime; !committed.IsZero() {
+		s.Git.LastCommitTime = &committed
+	}
+	s.Git.LastCommitAuthor = status.LastCommitAuthor
+	s.Git.LastCommitSubject = status.LastCommitSubject
+}
+
+// applyUsage copies OAuth usage into state and updates the 5h burn-down projection
+func applyUsage(s *state.State, cfg *config.Config, cached *oauth.CachedUsage) {
+	usage := cached.Usage
+
+	s.RateLimits.FiveHourPercent = usage.FiveHour.Utilization
+	s.RateLimits.SevenDayPercent = usage.SevenDay.Utilization
+	s.RateLimits.FiveHourResetsAt = usage.FiveHour.ResetsAt.Format(time.RFC3339)
+	s.RateLimits.SevenDayResetsAt = usage.SevenDay.ResetsAt.Format(time.RFC3339)
+	s.RateLimits.FromOAuth = true
+	s.RateLimits.Stale = cached.Stale
+	// The marker stays while a probe checks whether the API is back
+	s.RateLimits.Offline = offlineForced(cfg) ||
+		(cfg.OAuth.OfflineAfter > 0 && cached.NetworkFailures >= cfg.OAuth.OfflineAfter)
+
+	s.RateLimits.Buckets = make(map[string]state.RateLimitBucket, len(usage.Buckets))
+	for name, bucket := range usage.Buckets {
+		resetsAt := ""
+		if !bucket.ResetsAt.IsZero() {
+			resetsAt = bucket.ResetsAt.Format(time.RFC3339)
+		}
+		s.RateLimits.Buckets[name] = state.RateLimitBucket{Percent: bucket.Utilization, ResetsAt: resetsAt}
+	}
+	if usage.ExtraUsage != nil {
+		s.RateLimits.ExtraUsage.Enabled = usage.ExtraUsage.IsEnabled
+		s.RateLimits.ExtraUsage.Percent = usage.ExtraUsage.Utilization
+	}
+
+	// Project when the 5h limit will be hit at the recent burn rate
+	// (sampled at fetch time so cached values don't flatten the rate)
+	limitAt, ok, err := burndown.Update(s.RateLimits.FiveHourPercent, s.RateLimits.FiveHourResetsAt, cached.FetchedAt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update 5h history: %v\n", err)
+	}
+	if ok && !cached.Stale {
+		s.RateLimits.FiveHourLimitAt = limitAt.Format(time.RFC3339)
+	}
+}
@@ -2,9 +2,18 @@ package main
 
 import (
 	"bytes"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/internal/git"
+	"github.com/huyhandes/cc-hud-go/internal/oauth"
+	"github.com/huyhandes/cc-hud-go/internal/store"
+	"github.com/huyhandes/cc-hud-go/state"
 )
 
 func TestVersionFlag(t *testing.T) {
@@ -126,3 +135,94 @@ func TestUsageFunction(t *testing.T) {
 	// This test just ensures no panic occurs
 	printUsage()
 }
+
+func TestExtraRepos(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	cfg := config.Default()
+	cfg.Git.ExtraRepos = []string{"../api", "", "~/src/web", "/abs/lib", "../api", "."}
+
+	got := extraRepos(cfg, "/work/app")
+	want := []string{"/work/api", filepath.Join(home, "src", "web"), "/abs/lib"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("extraRepos() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyRepos(t *testing.T) {
+	s := state.New()
+	dirs := []string{"/work/api", "/work/not-a-repo", "/work/slow", "/work/missing"}
+	entries := map[string]*git.CacheEntry{
+		"/work/api":        {Branch: "main", Status: &git.Status{DirtyFiles: 2}},
+		"/work/not-a-repo": {},
+		"/work/slow":       {TimedOut: true},
+	}
+
+	applyRepos(s, dirs, entries)
+
+	want := []state.RepoInfo{
+		{Name: "api", Branch: "main", DirtyFiles: 2},
+		{Name: "slow", TimedOut: true},
+	}
+	if len(s.Git.Repos) != len(want) {
+		t.Fatalf("expected %d repos, got %+v", len(want), s.Git.Repos)
+	}
+	for i := range want {
+		if s.Git.Repos[i] != want[i] {
+			t.Errorf("repo %d = %+v, want %+v", i, s.Git.Repos[i], want[i])
+		}
+	}
+}
+
+func TestOAuthOfflineDetected(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	t.Setenv(oauth.OfflineEnv, "")
+	now := time.Now()
+	cfg := config.Default()
+
+	if oauthOffline(cfg, now) {
+		t.Fatal("expected online without failures")
+	}
+
+	if err := oauth.SaveCache(&oauth.CacheEntry{NetworkFailures: cfg.OAuth.OfflineAfter, FailedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if !oauthOffline(cfg, now) {
+		t.Error("expected offline after repeated network failures")
+	}
+
+	cfg.OAuth.OfflineAfter = 0
+	if oauthOffline(cfg, now) {
+		t.Error("expected detection to be disabled with offlineAfter 0")
+	}
+}
+
+func TestLoadSessionCommitsReadsLedgerOnly(t *testing.T) {
+	t.Setenv(store.DirEnv, t.TempDir())
+	cfg := config.Default()
+	s := state.New()
+	s.Session.ID = "abc"
+
+	if loadSessionCommits(s, cfg, "/repo", &git.Status{HeadOID: "head-1"}) {
+		t.Error("expected a refresh to be due for an unrecorded session")
+	}
+
+	// The provider is never asked for commits on a first sighting
+	if _, err := git.SessionCommits(nil, "abc", "/repo", "head-1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if !loadSessionCommits(s, cfg, "/repo", &git.Status{HeadOID: "head-1"}) {
+		t.Error("expected the ledger to cover HEAD")
+	}
+	if loadSessionCommits(s, cfg, "/repo", &git.Status{HeadOID: "head-2"}) {
+		t.Error("expected a refresh to be due once HEAD moved")
+	}
+
+	cfg.Git.ShowSessionCommits = false
+	if !loadSessionCommits(s, cfg, "/repo", &git.Status{HeadOID: "head-2"}) {
+		t.Error("expected nothing due with session commits hidden")
+	}
+}
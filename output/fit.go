@@ -0,0 +1,105 @@
+package output
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+// item is one piece of a line, with what to show when the line is too wide
+type item struct {
+	text     string
+	compact  string // Shorter form, "" = drop instead of shrinking
+	priority int    // Lower priorities are shrunk, then dropped, first
+}
+
+// fitLine joins items with sep into a line no wider than width (0 =
+// unbounded). It shrinks items to their compact form, lowest priority first,
+// then drops them, and truncates what is left if a single item is still too wide.
+func fitLine(items []item, width int, sep separator) string {
+	texts := make([]string, len(items))
+	for i, it := range items {
+		texts[i] = it.text
+	}
+	line := sep.join(texts)
+	if width <= 0 || lipgloss.Width(line) <= width {
+		return line
+	}
+
+	// Lowest priority first; on a tie, the rightmost item goes first
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = len(items) - 1 - i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return items[order[a]].priority < items[order[b]].priority
+	})
+
+	for _, i := range order {
+		if items[i].compact == "" || items[i].compact == texts[i] {
+			continue
+		}
+		texts[i] = items[i].compact
+		if line = sep.join(texts); lipgloss.Width(line) <= width {
+			return line
+		}
+	}
+
+	kept := len(nonEmpty(texts))
+	for _, i := range order {
+		if kept <= 1 {
+			break
+		}
+		if strings.TrimSpace(texts[i]) == "" {
+			continue
+		}
+		texts[i] = ""
+		kept--
+		if line = sep.join(texts); lipgloss.Width(line) <= width {
+			return line
+		}
+	}
+
+	return truncateLines(sep.join(texts), width)
+}
+
+// truncateLines cuts each line of s to width display cells, ending cut lines with "…"
+func truncateLines(s string, width int) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if lipgloss.Width(line) > width {
+			lines[i] = ansi.Truncate(line, width, "…")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TerminalWidth returns the columns available to the statusline: $COLUMNS
+// when set, otherwise the size of the terminal (0 when unknown). Claude Code
+// pipes stdin and stdout, so the controlling terminal is asked last.
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if width, _, err := term.GetSize(f.Fd()); err == nil && width > 0 {
+			return width
+		}
+	}
+
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = tty.Close() }()
+	if width, _, err := term.GetSize(tty.Fd()); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
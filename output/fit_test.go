@@ -0,0 +1,127 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+func TestFitLine(t *testing.T) {
+	items := []item{
+		{text: "🤖 Sonnet 4.5", compact: "Sonnet 4.5", priority: 90},
+		{text: "🔧 App 12 MCP 3", compact: "🔧 15", priority: 10},
+		{text: "💰$1.50 ⏱ 2m", compact: "💰$1.50", priority: 60},
+	}
+
+	tests := []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{"unbounded", 0, "🤖 Sonnet 4.5  │  🔧 App 12 MCP 3  │  💰$1.50 ⏱ 2m"},
+		{"fits", 60, "🤖 Sonnet 4.5  │  🔧 App 12 MCP 3  │  💰$1.50 ⏱ 2m"},
+		{"shrinks lowest priority first", 42, "🤖 Sonnet 4.5  │  🔧 15  │  💰$1.50 ⏱ 2m"},
+		{"shrinks in priority order", 35, "🤖 Sonnet 4.5  │  🔧 15  │  💰$1.50"},
+		{"drops lowest priority", 25, "Sonnet 4.5  │  💰$1.50"},
+		{"keeps one item", 12, "Sonnet 4.5"},
+		{"truncates the last item", 6, "Sonne…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitLine(items, tt.width, barSeparator)
+			if got != tt.want {
+				t.Errorf("fitLine(%d) = %q, want %q", tt.width, got, tt.want)
+			}
+			if tt.width > 0 && lipgloss.Width(got) > tt.width {
+				t.Errorf("fitLine(%d) is %d cells wide", tt.width, lipgloss.Width(got))
+			}
+		})
+	}
+}
+
+func TestFitLineDropsRightmostOnTie(t *testing.T) {
+	items := []item{
+		{text: "aaaa", priority: 10},
+		{text: "bbbb", priority: 10},
+	}
+	if got := fitLine(items, 6, barSeparator); got != "aaaa" {
+		t.Errorf("expected the rightmost item dropped, got %q", got)
+	}
+}
+
+func TestTruncateLinesMeasuresEmojiAndANSI(t *testing.T) {
+	styled := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Render("🌿 main") + " ok"
+	got := truncateLines(styled+"\n📋 tasks", 5)
+	for _, line := range strings.Split(got, "\n") {
+		if w := lipgloss.Width(line); w > 5 {
+			t.Errorf("line %q is %d cells wide, want at most 5", line, w)
+		}
+	}
+}
+
+func TestRenderFitsWidth(t *testing.T) {
+	s := state.New()
+	s.Model.Name = "Opus 4.6"
+	s.Context.UsedTokens = 50000
+	s.Context.TotalTokens = 200000
+	s.Context.TotalInputTokens = 30000
+	s.Context.TotalOutputTokens = 10000
+	s.Context.CacheReadTokens = 5000
+	s.Context.CacheCreateTokens = 3000
+	s.Cost.TotalUSD = 0.0567
+	s.Cost.DurationMs = 154000
+	s.Cost.LinesAdded = 45
+	s.Git.Branch = "feature/terminal-width"
+	s.Git.DirtyFiles = 4
+	s.Tools.AppTools = map[string]int{"Read": 10, "Edit": 4}
+	s.Tasks.Pending = 3
+
+	for _, layout := range []string{"compact", "multiline"} {
+		for _, width := range []int{30, 50, 80} {
+			cfg := config.Default()
+			cfg.LineLayout = layout
+			cfg.Width = width
+
+			out, err := Render(s, cfg)
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+			if !strings.Contains(out, "Opus") {
+				t.Errorf("%s at %d: expected the model to survive, got:\n%s", layout, width, out)
+			}
+			for _, line := range strings.Split(out, "\n") {
+				if w := lipgloss.Width(line); w > width {
+					t.Errorf("%s at %d: line is %d cells wide: %q", layout, width, w, line)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderNarrowReplacesBoxes(t *testing.T) {
+	cfg := config.Default()
+	cfg.LineLayout = "multiline"
+	cfg.Width = 20
+	s := state.New()
+	s.Model.Name = "Haiku"
+	s.Tools.AppTools = map[string]int{"Read": 10, "Edit": 4}
+
+	out, err := Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if strings.Contains(out, "╭") || !strings.Contains(out, "🔧 14") {
+		t.Errorf("expected the tools box replaced by its compact form, got:\n%s", out)
+	}
+}
+
+func TestTerminalWidthFromColumns(t *testing.T) {
+	t.Setenv("COLUMNS", "123")
+	if got := TerminalWidth(); got != 123 {
+		t.Errorf("TerminalWidth() = %d, want 123", got)
+	}
+}
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/segment"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatEnv  = "env"
+)
+
+// envPrefix starts every variable of the env format
+const envPrefix = "CC_HUD_"
+
+// document is the json format: the derived state and each enabled segment's
+// rendering without colors
+type document struct {
+	State    *state.State      `json:"state"`
+	Segments map[string]string `json:"segments"`
+}
+
+// newDocument renders the enabled segments as plain text
+func newDocument(s *state.State, cfg *config.Config) (document, error) {
+	doc := document{State: s, Segments: make(map[string]string)}
+	for _, seg := range segment.All() {
+		if !seg.Enabled(cfg) {
+			continue
+		}
+		text, err := seg.Render(s, cfg)
+		if err != nil {
+			return document{}, err
+		}
+		doc.Segments[seg.ID()] = ansi.Strip(text)
+	}
+	return doc, nil
+}
+
+// renderJSON renders the state and segments as indented JSON
+func renderJSON(s *state.State, cfg *config.Config) (string, error) {
+	doc, err := newDocument(s, cfg)
+	if err != nil {
+		return "", err
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding json: %w", err)
+	}
+	return string(out), nil
+}
+
+// renderEnv renders the json format flattened into sorted KEY=value lines a
+// shell can source: state fields become CC_HUD_CONTEXT_USED_TOKENS and so on,
+// segments CC_HUD_SEGMENT_GIT. Null fields and empty collections are left out.
+func renderEnv(s *state.State, cfg *config.Config) (string, error) {
+	doc, err := newDocument(s, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	// Round-trip through JSON so the names match the json format
+	data, err := json.Marshal(doc.State)
+	if err != nil {
+		return "", fmt.Errorf("encoding json: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // Keep numbers as they were encoded
+	var fields interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return "", fmt.Errorf("decoding json: %w", err)
+	}
+
+	vars := make(map[string]string)
+	flatten(vars, strings.TrimSuffix(envPrefix, "_"), fields)
+	for id, text := range doc.Segments {
+		vars[envPrefix+"SEGMENT_"+envName(id)] = text
+	}
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + "=" + shellQuote(vars[key])
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// flatten adds the scalars of a decoded JSON value to vars, naming each by
+// its path from prefix
+func flatten(vars map[string]string, prefix string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flatten(vars, prefix+"_"+envName(key), child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(vars, fmt.Sprintf("%s_%d", prefix, i), child)
+		}
+	case nil:
+	default:
+		vars[prefix] = fmt.Sprint(v)
+	}
+}
+
+// envName converts a JSON name or map key to an environment variable name:
+// "usedTokens" becomes USED_TOKENS and "mcp:github" MCP_GITHUB
+func envName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if !isEnvRune(r) {
+			b.WriteByte('_')
+			continue
+		}
+		// Acronyms stay whole: "fromOAuth" becomes FROM_OAUTH
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// isEnvRune reports whether r may appear in a portable variable name
+func isEnvRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// shellSafe matches values that need no quoting
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_.,:/+@%-]*$`)
+
+// shellQuote single-quotes s unless it is safe bare
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
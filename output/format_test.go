@@ -0,0 +1,160 @@
+package output
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// goldenState is a session with every kind of data, free of the clock
+func goldenState() *state.State {
+	s := state.New()
+	s.Model = state.ModelInfo{ID: "claude-sonnet-4-5", Name: "Sonnet 4.5", PlanType: "max"}
+	s.Context.UsedTokens = 90000
+	s.Context.TotalTokens = 200000
+	s.Context.TotalInputTokens = 120000
+	s.Context.TotalOutputTokens = 8000
+	s.Context.CacheReadTokens = 60000
+	s.Context.CacheCreateTokens = 10000
+	s.Context.CurrentInputTokens = 20000
+	s.Context.TurnHistory = []int{60000, 75000, 90000}
+	s.RateLimits.FiveHourPercent = 42
+	s.RateLimits.SevenDayPercent = 12
+	s.RateLimits.FromOAuth = true
+	s.Git.Branch = "feature/json"
+	s.Git.DirtyFiles = 3
+	s.Git.Ahead = 2
+	s.Git.Modified = 2
+	s.Git.Untracked = 1
+	s.Git.Head = "1a2b3c4"
+	s.Git.Total = state.LineStats{Added: 40, Removed: 7}
+	s.Git.LastCommitSubject = "Don't panic"
+	s.Tools.AppTools["Read"] = 5
+	s.Tools.AppTools["Edit"] = 2
+	s.Tools.MCPTools[state.MCPServer{Name: "github", Type: "mcp"}] = map[string]int{"create_issue": 1}
+	s.Tasks = state.TaskInfo{
+		Pending:   1,
+		Completed: 1,
+		Details: []state.Task{
+			{Subject: "Write docs", Status: "pending"},
+			{Subject: "Add flag", Status: "completed"},
+		},
+	}
+	s.Session.ID = "3f2a9c1e"
+	s.Session.WorkDir = "/home/ada/project"
+	s.Cost = state.CostInfo{TotalUSD: 1.25, DurationMs: 600000, LinesAdded: 40, LinesRemoved: 7}
+	return s
+}
+
+// checkGolden compares got with testdata/name, rewriting it with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if got+"\n" != string(want) {
+		t.Errorf("%s mismatch (run with -update if intended)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		golden string
+	}{
+		{FormatJSON, "state.json.golden"},
+		{FormatEnv, "state.env.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.OutputFormat = tt.format
+
+			got, err := Render(goldenState(), cfg)
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+			checkGolden(t, tt.golden, got)
+		})
+	}
+}
+
+func TestRenderJSONRoundTrips(t *testing.T) {
+	cfg := config.Default()
+	cfg.OutputFormat = FormatJSON
+
+	out, err := Render(goldenState(), cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	var doc struct {
+		State    state.State
+		Segments map[string]string
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if got := doc.State.Tools.MCPTools[state.MCPServer{Name: "github", Type: "mcp"}]["create_issue"]; got != 1 {
+		t.Errorf("MCP tool count = %d, want 1", got)
+	}
+	if doc.State.Git.LastCommitTime != nil {
+		t.Errorf("expected no last commit time, got %v", doc.State.Git.LastCommitTime)
+	}
+	if doc.Segments["model"] == "" {
+		t.Error("expected the model segment's text")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"usedTokens":     "USED_TOKENS",
+		"fromOAuth":      "FROM_OAUTH",
+		"totalUSD":       "TOTAL_USD",
+		"apiDurationMs":  "API_DURATION_MS",
+		"mcp:github":     "MCP_GITHUB",
+		"seven_day_opus": "SEVEN_DAY_OPUS",
+		"fiveHour":       "FIVE_HOUR",
+	}
+	for in, want := range tests {
+		if got := envName(in); got != want {
+			t.Errorf("envName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":              "",
+		"1.25":          "1.25",
+		"feature/json":  "feature/json",
+		"Sonnet 4.5":    "'Sonnet 4.5'",
+		"Don't panic":   `'Don'\''t panic'`,
+		"$(rm -rf ~)":   "'$(rm -rf ~)'",
+		"line\nbreaker": "'line\nbreaker'",
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -6,17 +6,25 @@ import (
 
 	"github.com/huyhandes/cc-hud-go/config"
 	"github.com/huyhandes/cc-hud-go/format"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/segment"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
 
-// Render generates plain text output for the statusline
-// Returns plain text that Claude Code will display
+// Render generates the statusline, or the state in a machine-readable
+// format when cfg.OutputFormat is "json" or "env"
 func Render(s *state.State, cfg *config.Config) (string, error) {
 	// Update derived fields before rendering
 	s.UpdateDerived()
 
+	switch cfg.OutputFormat {
+	case FormatJSON:
+		return renderJSON(s, cfg)
+	case FormatEnv:
+		return renderEnv(s, cfg)
+	}
+
 	// Check if multi-line layout is requested
 	if cfg.LineLayout == "multiline" || cfg.LineLayout == "expanded" {
 		return renderMultiLine(s, cfg)
@@ -27,7 +35,7 @@ func Render(s *state.State, cfg *config.Config) (string, error) {
 }
 
 func renderSingleLine(s *state.State, cfg *config.Config) (string, error) {
-	var parts []string
+	var items []item
 
 	// Render all segments
 	for _, seg := range segment.All() {
@@ -35,98 +43,122 @@ func renderSingleLine(s *state.State, cfg *config.Config) (string, error) {
 			continue
 		}
 
-		text, err := seg.Render(s, cfg)
+		it, err := renderItem(seg, s, cfg)
 		if err != nil {
 			return "", err
 		}
 
-		if text == "" {
+		if it.text == "" {
 			continue
 		}
 
-		parts = append(parts, text)
+		items = append(items, it)
 	}
 
-	return joinSegments(parts), nil
+	return fitLine(items, cfg.Width, lineSeparator(cfg, 0)), nil
+}
+
+// renderItem renders a segment in full and compact form
+func renderItem(seg segment.Segment, s *state.State, cfg *config.Config) (item, error) {
+	text, err := seg.Render(s, cfg)
+	if err != nil || text == "" {
+		return item{}, err
+	}
+	compact, err := seg.Compact(s, cfg)
+	if err != nil {
+		return item{}, err
+	}
+	return item{text: text, compact: compact, priority: seg.Priority()}, nil
 }
 
+// Priorities of the pieces the multi-line layout renders itself, between
+// those of the segments (see segment.Segment)
+const (
+	priorityFileChanges = 25
+	priorityIOTokens    = 15
+)
+
 func renderMultiLine(s *state.State, cfg *config.Config) (string, error) {
 	var lines []string
 	segs := segment.ByID()
 
-	renderSeg := func(id string) string {
+	renderSeg := func(id string) item {
 		seg, ok := segs[id]
 		if !ok || !seg.Enabled(cfg) {
-			return ""
+			return item{}
+		}
+		it, _ := renderItem(seg, s, cfg)
+		return it
+	}
+
+	// line is the slot in the layout below, which picks its separator
+	addLine := func(line int, items []item) {
+		var kept []item
+		for _, it := range items {
+			if it.text != "" {
+				kept = append(kept, it)
+			}
+		}
+		if len(kept) > 0 {
+			lines = append(lines, fitLine(kept, cfg.Width, lineSeparator(cfg, line)))
 		}
-		text, _ := seg.Render(s, cfg)
-		return text
 	}
 
-	// Line 1: Model Context Size | Context Bar | 5h Limit | 7d Limit
-	line1 := []string{}
+	hasContext := cfg.Display.Context && s.Context.TotalTokens > 0
 
+	// Line 1: Model Context Size | Context Bar | Compaction | 5h Limit | 7d Limit | Other Limits
 	modelAndContext := renderSeg("model")
-	if cfg.Display.Context && s.Context.TotalTokens > 0 {
+	if hasContext {
 		ctxSize := renderContextSize(s)
-		if modelAndContext != "" {
-			modelAndContext += " " + ctxSize
+		if modelAndContext.text != "" {
+			modelAndContext.text += " " + ctxSize
 		} else {
-			modelAndContext = ctxSize
+			modelAndContext = item{text: ctxSize, priority: segs["model"].Priority()}
 		}
 	}
-	if modelAndContext != "" {
-		line1 = append(line1, modelAndContext)
-	}
 
-	if cfg.Display.Context && s.Context.TotalTokens > 0 {
-		line1 = append(line1, renderContextBar(s))
-	}
-	if text := renderSeg("fivehour"); text != "" {
-		line1 = append(line1, text)
-	}
-	if text := renderSeg("ratelimit"); text != "" {
-		line1 = append(line1, text)
-	}
-	if len(line1) > 0 {
-		lines = append(lines, joinSegments(line1))
+	line1 := []item{modelAndContext}
+	if hasContext {
+		line1 = append(line1, item{
+			text:     renderContextBar(s),
+			compact:  renderContextPercent(s),
+			priority: segs["context"].Priority(),
+		})
 	}
+	line1 = append(line1, renderSeg("compaction"), renderSeg("fivehour"), renderSeg("ratelimit"), renderSeg("limits"))
+	addLine(0, line1)
 
-	// Line 2: Input/Output | Cache Read/Write | Cost | Time
-	line2 := []string{}
-	if cfg.Display.Context && s.Context.TotalTokens > 0 {
-		line2 = append(line2, renderIOTokens(s))
+	// Line 2: Input/Output | Cache Read/Write | Cost | Time | Budget
+	line2 := []item{}
+	if hasContext {
+		line2 = append(line2, item{text: renderIOTokens(s), priority: priorityIOTokens})
 		if s.Context.CacheReadTokens > 0 || s.Context.CacheCreateTokens > 0 {
-			line2 = append(line2, renderCacheTokens(s))
+			line2 = append(line2, item{
+				text:     renderCacheTokens(s),
+				compact:  icons.Label("cache", segment.CacheHitRate(s)),
+				priority: segs["cache"].Priority(),
+			})
 		}
 	}
 	if s.Cost.TotalUSD > 0 {
-		line2 = append(line2, renderCost(s))
+		line2 = append(line2, item{text: renderCost(s), priority: segs["cost"].Priority()})
 	}
 	if s.Cost.DurationMs > 0 {
-		line2 = append(line2, renderTime(s))
-	}
-	if len(line2) > 0 {
-		lines = append(lines, joinSegments(line2))
+		line2 = append(line2, item{text: renderTime(s), priority: segs["duration"].Priority()})
 	}
+	line2 = append(line2, renderSeg("budget"))
+	addLine(1, line2)
 
 	// Line 3: Git | File changes
-	line3 := []string{}
-	if text := renderSeg("git"); text != "" {
-		line3 = append(line3, text)
-	}
+	line3 := []item{renderSeg("git")}
 	if s.Cost.LinesAdded > 0 || s.Cost.LinesRemoved > 0 {
-		line3 = append(line3, renderFileChanges(s))
-	}
-	if len(line3) > 0 {
-		lines = append(lines, joinSegments(line3))
+		line3 = append(line3, item{text: renderFileChanges(s), priority: priorityFileChanges})
 	}
+	addLine(2, line3)
 
 	// Line 4+: Each tool/task segment on its own line
-	for _, id := range []string{"tools", "tasks", "agent"} {
-		if text := renderSeg(id); text != "" {
-			lines = append(lines, text)
-		}
+	for i, id := range []string{"tools", "tasks", "agent"} {
+		addLine(3+i, []item{renderSeg(id)})
 	}
 
 	return strings.Join(lines, "\n"), nil
@@ -135,7 +167,7 @@ func renderMultiLine(s *state.State, cfg *config.Config) (string, error) {
 // renderContextSize renders just the total context window size
 func renderContextSize(s *state.State) string {
 	totalStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInfo)
-	return fmt.Sprintf("⚡ %s", totalStyle.Render(format.Tokens(s.Context.TotalTokens)))
+	return icons.Label("contextSize", totalStyle.Render(format.Tokens(s.Context.TotalTokens)))
 }
 
 // renderContextBar renders just the progress bar and percentage
@@ -148,7 +180,13 @@ func renderContextBar(s *state.State) string {
 	percentageStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(percentage))
 	percentageText := percentageStyle.Render(fmt.Sprintf("%.0f%%", percentage))
 
-	return fmt.Sprintf("🧠 %s %s", bar, percentageText)
+	return icons.Label("context", bar+" "+percentageText)
+}
+
+// renderContextPercent renders the context percentage without the bar
+func renderContextPercent(s *state.State) string {
+	percentageStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(s.Context.Percentage))
+	return icons.Label("context", percentageStyle.Render(fmt.Sprintf("%.0f%%", s.Context.Percentage)))
 }
 
 // renderIOTokens renders input/output token counts
@@ -156,32 +194,33 @@ func renderIOTokens(s *state.State) string {
 	inStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInput)
 	outStyle := style.GetRenderer().NewStyle().Foreground(style.ColorOutput)
 
-	return fmt.Sprintf("📥 %s  📤 %s",
-		inStyle.Render(format.Tokens(s.Context.TotalInputTokens)),
-		outStyle.Render(format.Tokens(s.Context.TotalOutputTokens)))
+	return icons.Label("input", inStyle.Render(format.Tokens(s.Context.TotalInputTokens))) + "  " +
+		icons.Label("output", outStyle.Render(format.Tokens(s.Context.TotalOutputTokens)))
 }
 
-// renderCacheTokens renders cache read/write token counts
+// renderCacheTokens renders cache read/write token counts with hit ratio and savings
 func renderCacheTokens(s *state.State) string {
 	cacheReadStyle := style.GetRenderer().NewStyle().Foreground(style.ColorCacheRead)
 	cacheWriteStyle := style.GetRenderer().NewStyle().Foreground(style.ColorCacheWrite)
 
-	return fmt.Sprintf("💾 %s%s%s",
+	return icons.Label("cache", fmt.Sprintf("%s%s%s %s %s",
 		cacheReadStyle.Render("R:"+format.Tokens(s.Context.CacheReadTokens)),
 		style.GetRenderer().NewStyle().Foreground(style.ColorMuted).Render("/"),
-		cacheWriteStyle.Render("W:"+format.Tokens(s.Context.CacheCreateTokens)))
+		cacheWriteStyle.Render("W:"+format.Tokens(s.Context.CacheCreateTokens)),
+		segment.CacheHitRate(s),
+		segment.CacheSavings(s)))
 }
 
 // renderCost renders the total cost
 func renderCost(s *state.State) string {
 	costStyle := style.GetRenderer().NewStyle().Foreground(style.ColorAccent).Bold(true)
-	return costStyle.Render("💰" + format.Cost(s.Cost.TotalUSD))
+	return costStyle.Render(icons.Get("cost") + format.Cost(s.Cost.TotalUSD))
 }
 
 // renderTime renders the session duration
 func renderTime(s *state.State) string {
 	durationStyle := style.GetRenderer().NewStyle().Foreground(style.ColorHighlight)
-	return durationStyle.Render("⏱ " + format.Duration(s.Cost.DurationMs))
+	return durationStyle.Render(icons.Label("duration", format.Duration(s.Cost.DurationMs)))
 }
 
 // renderFileChanges renders file changes (lines added/removed)
@@ -193,22 +232,20 @@ func renderFileChanges(s *state.State) string {
 	addStyle := style.GetRenderer().NewStyle().Foreground(style.ColorSuccess)
 	removeStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger)
 
-	return fmt.Sprintf("📝 %s%s%s",
+	return icons.Label("fileChanges", fmt.Sprintf("%s%s%s",
 		addStyle.Render(fmt.Sprintf("+%d", s.Cost.LinesAdded)),
 		style.GetRenderer().NewStyle().Foreground(style.ColorMuted).Render("/"),
 		removeStyle.Render(fmt.Sprintf("-%d", s.Cost.LinesRemoved)),
-	)
+	))
 }
 
-// joinSegments joins segment outputs with two-space separators
-func joinSegments(segments []string) string {
-	// Filter out empty segments
-	nonEmpty := make([]string, 0, len(segments))
+// nonEmpty filters out blank segments
+func nonEmpty(segments []string) []string {
+	kept := make([]string, 0, len(segments))
 	for _, seg := range segments {
 		if strings.TrimSpace(seg) != "" {
-			nonEmpty = append(nonEmpty, seg)
+			kept = append(kept, seg)
 		}
 	}
-
-	return strings.Join(nonEmpty, "  │  ")
+	return kept
 }
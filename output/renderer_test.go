@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 	"github.com/huyhandes/cc-hud-go/theme"
@@ -341,47 +342,34 @@ func TestRenderFileChanges(t *testing.T) {
 	}
 }
 
-func TestJoinSegmentsWithSpacing(t *testing.T) {
-	segments := []string{"segment1", "segment2", "segment3"}
+func TestRenderASCIIIcons(t *testing.T) {
+	icons.Init("ascii", nil)
+	defer icons.Init("emoji", nil)
 
-	result := joinSegments(segments)
-
-	if !strings.Contains(result, "  │  ") {
-		t.Errorf("Expected two-space separator, got: %s", result)
-	}
+	cfg := config.Default()
+	cfg.LineLayout = "multiline"
+	s := state.New()
+	s.Model.Name = "Opus 4.6"
+	s.Context.UsedTokens = 50000
+	s.Context.TotalTokens = 200000
+	s.Context.TotalInputTokens = 30000
+	s.Context.TotalOutputTokens = 10000
+	s.Cost.TotalUSD = 0.5
+	s.Cost.DurationMs = 60000
+	s.Git.Branch = "main"
 
-	sepCount := strings.Count(result, "│")
-	if sepCount != 2 {
-		t.Errorf("Expected 2 separators, got %d", sepCount)
+	output, err := Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
 	}
-}
-
-func TestJoinSegmentsEdgeCases(t *testing.T) {
-	t.Run("empty input", func(t *testing.T) {
-		result := joinSegments(nil)
-		if result != "" {
-			t.Errorf("expected empty, got: %s", result)
-		}
-	})
-
-	t.Run("single segment", func(t *testing.T) {
-		result := joinSegments([]string{"only"})
-		if result != "only" {
-			t.Errorf("expected 'only', got: %s", result)
+	for _, emoji := range []string{"🤖", "🧠", "📥", "💰", "⏱", "🌿"} {
+		if strings.Contains(output, emoji) {
+			t.Errorf("expected no %s with the ascii set, got: %s", emoji, output)
 		}
-	})
-
-	t.Run("all empty", func(t *testing.T) {
-		result := joinSegments([]string{"", "", "  "})
-		if result != "" {
-			t.Errorf("expected empty after filtering, got: %s", result)
-		}
-	})
-
-	t.Run("filters empty between segments", func(t *testing.T) {
-		result := joinSegments([]string{"a", "", "b"})
-		if strings.Count(result, "│") != 1 {
-			t.Errorf("expected 1 separator, got: %s", result)
+	}
+	for _, label := range []string{"* ", "in ", "git main"} {
+		if !strings.Contains(output, label) {
+			t.Errorf("expected %q with the ascii set, got: %s", label, output)
 		}
-	})
+	}
 }
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/style"
+)
+
+// Separator styles
+const (
+	separatorBar       = "bar"
+	separatorPowerline = "powerline"
+	separatorRounded   = "rounded"
+	separatorCustom    = "custom"
+)
+
+// Powerline glyphs (Nerd Fonts and Powerline-patched fonts)
+const (
+	powerlineArrow = "" // Solid right arrow
+	capsuleLeft    = "" // Left half circle
+	capsuleRight   = "" // Right half circle
+)
+
+// separator joins the segments of one line
+type separator struct {
+	style  string
+	custom string // Placed between segments with the custom style
+}
+
+// barSeparator is the default: segments between plain bars
+var barSeparator = separator{style: separatorBar}
+
+// lineSeparator returns the separator for a line of the layout (0-based),
+// falling back to the overall style
+func lineSeparator(cfg *config.Config, line int) separator {
+	sep := separator{style: cfg.Separator.Style, custom: cfg.Separator.Custom}
+	if line < len(cfg.Separator.Lines) && cfg.Separator.Lines[line] != "" {
+		sep.style = cfg.Separator.Lines[line]
+	}
+	return sep
+}
+
+// join joins the non-blank segments. Backgrounds can't span lines, so a line
+// holding a multi-line segment (a boxed view) falls back to bars.
+func (sep separator) join(segments []string) string {
+	segments = nonEmpty(segments)
+
+	switch sep.style {
+	case separatorPowerline, separatorRounded:
+		for _, seg := range segments {
+			if strings.Contains(seg, "\n") {
+				return barSeparator.join(segments)
+			}
+		}
+		if sep.style == separatorPowerline {
+			return joinPowerline(segments)
+		}
+		return joinCapsules(segments)
+	case separatorCustom:
+		return strings.Join(segments, sep.custom)
+	default:
+		return strings.Join(segments, "  │  ")
+	}
+}
+
+// joinPowerline paints segments on alternating backgrounds, each ending in an
+// arrow into the next
+func joinPowerline(segments []string) string {
+	var b strings.Builder
+	for i, seg := range segments {
+		bg := segmentBackground(i)
+		b.WriteString(style.WithBackground(" "+seg+" ", bg))
+
+		arrow := style.GetRenderer().NewStyle().Foreground(bg)
+		if i+1 < len(segments) {
+			arrow = arrow.Background(segmentBackground(i + 1))
+		}
+		b.WriteString(arrow.Render(powerlineArrow))
+	}
+	return b.String()
+}
+
+// joinCapsules paints each segment on its own rounded background
+func joinCapsules(segments []string) string {
+	capsules := make([]string, len(segments))
+	for i, seg := range segments {
+		bg := segmentBackground(i)
+		capStyle := style.GetRenderer().NewStyle().Foreground(bg)
+		capsules[i] = capStyle.Render(capsuleLeft) + style.WithBackground(seg, bg) + capStyle.Render(capsuleRight)
+	}
+	return strings.Join(capsules, " ")
+}
+
+// segmentBackground alternates between the theme's surface colors
+func segmentBackground(i int) lipgloss.Color {
+	if i%2 == 0 {
+		return style.ColorSurface
+	}
+	return style.ColorSurfaceAlt
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+	"github.com/huyhandes/cc-hud-go/style"
+)
+
+func TestLineSeparator(t *testing.T) {
+	cfg := config.Default()
+	cfg.Separator = config.SeparatorConfig{Style: "rounded", Custom: " · ", Lines: []string{"powerline", "", "custom"}}
+
+	tests := []struct {
+		line int
+		want string
+	}{
+		{0, "powerline"},
+		{1, "rounded"},
+		{2, "custom"},
+		{5, "rounded"},
+	}
+	for _, tt := range tests {
+		if got := lineSeparator(cfg, tt.line); got.style != tt.want || got.custom != " · " {
+			t.Errorf("lineSeparator(%d) = %+v, want style %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSeparatorJoin(t *testing.T) {
+	segments := []string{"a", "", "b", "c"}
+
+	if got := barSeparator.join(segments); got != "a  │  b  │  c" {
+		t.Errorf("bar join = %q", got)
+	}
+	if got := barSeparator.join([]string{"", "  "}); got != "" {
+		t.Errorf("expected blank segments to be dropped, got %q", got)
+	}
+	if got := (separator{style: separatorCustom, custom: " · "}).join(segments); got != "a · b · c" {
+		t.Errorf("custom join = %q", got)
+	}
+
+	powerline := separator{style: separatorPowerline}.join(segments)
+	if strings.Count(powerline, powerlineArrow) != 3 {
+		t.Errorf("expected an arrow after each segment, got %q", powerline)
+	}
+	if w := lipgloss.Width(powerline); w != 12 {
+		t.Errorf("expected padded segments and arrows 12 cells wide, got %d: %q", w, powerline)
+	}
+
+	rounded := separator{style: separatorRounded}.join(segments)
+	if strings.Count(rounded, capsuleLeft) != 3 || strings.Count(rounded, capsuleRight) != 3 {
+		t.Errorf("expected a capsule around each segment, got %q", rounded)
+	}
+
+	boxed := separator{style: separatorPowerline}.join([]string{"a", "╭─╮\n╰─╯"})
+	if strings.Contains(boxed, powerlineArrow) || !strings.Contains(boxed, "│") {
+		t.Errorf("expected multi-line segments to fall back to bars, got %q", boxed)
+	}
+}
+
+func TestPowerlineBackgrounds(t *testing.T) {
+	segments := []string{
+		style.GetRenderer().NewStyle().Foreground(lipgloss.Color("#ff0000")).Render("red") + " plain",
+		"next",
+	}
+	got := joinPowerline(segments)
+
+	surface := style.GetRenderer().ColorProfile().Color(string(style.ColorSurface)).Sequence(true)
+	surfaceAlt := style.GetRenderer().ColorProfile().Color(string(style.ColorSurfaceAlt)).Sequence(true)
+	if !strings.Contains(got, "\x1b[0m\x1b["+surface+"m plain") {
+		t.Errorf("expected the background reapplied after the inner reset, got %q", got)
+	}
+	if !strings.Contains(got, surfaceAlt) {
+		t.Errorf("expected the second segment on the alternate background, got %q", got)
+	}
+}
+
+func TestRenderSeparatorPerLine(t *testing.T) {
+	cfg := config.Default()
+	cfg.LineLayout = "multiline"
+	cfg.Separator = config.SeparatorConfig{Style: "custom", Custom: " ~ ", Lines: []string{"", "powerline"}}
+	s := state.New()
+	s.Model.Name = "Opus 4.6"
+	s.Context.UsedTokens = 50000
+	s.Context.TotalTokens = 200000
+	s.Cost.TotalUSD = 0.5
+	s.Cost.DurationMs = 60000
+
+	output, err := Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected at least 2 lines, got: %s", output)
+	}
+	if !strings.Contains(lines[0], " ~ ") || strings.Contains(lines[0], "│") {
+		t.Errorf("expected the custom separator on line 1, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], powerlineArrow) {
+		t.Errorf("expected powerline arrows on line 2, got %q", lines[1])
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"encoding/json"
 
+	"github.com/huyhandes/cc-hud-go/internal/models"
 	"github.com/huyhandes/cc-hud-go/state"
 )
 
@@ -67,7 +68,13 @@ func ParseStdin(data []byte, s *state.State) error {
 
 	s.Session.ID = stdin.SessionID
 	s.Session.TranscriptPath = stdin.TranscriptPath
+	s.Session.WorkDir = stdin.Workspace.CurrentDir
+	if s.Session.WorkDir == "" {
+		s.Session.WorkDir = stdin.CWD
+	}
+	s.Session.ProjectDir = stdin.Workspace.ProjectDir
 
+	s.Model.ID = stdin.Model.ID
 	s.Model.Name = stdin.Model.DisplayName
 	if s.Model.Name == "" {
 		s.Model.Name = stdin.Model.ID
@@ -78,6 +85,14 @@ func ParseStdin(data []byte, s *state.State) error {
 	s.Context.TotalOutputTokens = stdin.ContextWindow.TotalOutputTokens
 	s.Context.TotalTokens = stdin.ContextWindow.ContextWindowSize
 
+	// Older Claude Code versions omit the window size - derive it from the model
+	if s.Context.TotalTokens == 0 && stdin.Model.ID != "" {
+		s.Context.TotalTokens = models.Lookup(stdin.Model.ID, stdin.Model.DisplayName).ContextWindow
+		if stdin.Exceeds200KTokens && s.Context.TotalTokens <= 200_000 {
+			s.Context.TotalTokens = 1_000_000
+		}
+	}
+
 	if stdin.ContextWindow.CurrentUsage != nil {
 		s.Context.CurrentInputTokens = stdin.ContextWindow.CurrentUsage.InputTokens
 		s.Context.CacheCreateTokens = stdin.ContextWindow.CurrentUsage.CacheCreationInputTokens
@@ -261,3 +261,80 @@ func TestParseStdinNilOptionalFields(t *testing.T) {
 		t.Errorf("expected UsedTokens=5000 fallback, got %d", s.Context.UsedTokens)
 	}
 }
+
+func TestParseStdinContextWindowFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantWindow int
+	}{
+		{
+			name:       "explicit size wins",
+			input:      `{"model": {"id": "claude-sonnet-4-5[1m]"}, "context_window": {"context_window_size": 200000}}`,
+			wantWindow: 200000,
+		},
+		{
+			name:       "standard model",
+			input:      `{"model": {"id": "claude-sonnet-4-5-20250929", "display_name": "Sonnet 4.5"}, "context_window": {}}`,
+			wantWindow: 200000,
+		},
+		{
+			name:       "1m variant",
+			input:      `{"model": {"id": "claude-sonnet-4-5-20250929[1m]", "display_name": "Sonnet 4.5"}, "context_window": {}}`,
+			wantWindow: 1000000,
+		},
+		{
+			name:       "exceeds 200k implies long context",
+			input:      `{"model": {"id": "claude-sonnet-4-5"}, "context_window": {}, "exceeds_200k_tokens": true}`,
+			wantWindow: 1000000,
+		},
+		{
+			name:       "no model",
+			input:      `{"context_window": {}}`,
+			wantWindow: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := state.New()
+			if err := ParseStdin([]byte(tt.input), s); err != nil {
+				t.Fatalf("ParseStdin failed: %v", err)
+			}
+			if s.Context.TotalTokens != tt.wantWindow {
+				t.Errorf("expected TotalTokens %d, got %d", tt.wantWindow, s.Context.TotalTokens)
+			}
+		})
+	}
+}
+
+func TestParseStdinWorkspace(t *testing.T) {
+	input := `{
+		"cwd": "/project",
+		"workspace": {
+			"current_dir": "/project/vendor/lib",
+			"project_dir": "/project"
+		}
+	}`
+
+	s := state.New()
+	if err := ParseStdin([]byte(input), s); err != nil {
+		t.Fatalf("ParseStdin failed: %v", err)
+	}
+
+	if s.Session.WorkDir != "/project/vendor/lib" {
+		t.Errorf("expected WorkDir from workspace.current_dir, got %q", s.Session.WorkDir)
+	}
+	if s.Session.ProjectDir != "/project" {
+		t.Errorf("expected ProjectDir '/project', got %q", s.Session.ProjectDir)
+	}
+
+	// Older Claude Code versions only send cwd
+	s = state.New()
+	if err := ParseStdin([]byte(`{"cwd": "/project"}`), s); err != nil {
+		t.Fatalf("ParseStdin failed: %v", err)
+	}
+	if s.Session.WorkDir != "/project" {
+		t.Errorf("expected WorkDir to fall back to cwd, got %q", s.Session.WorkDir)
+	}
+}
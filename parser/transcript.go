@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"os"
 	"strings"
@@ -11,14 +12,46 @@ import (
 
 // TranscriptLine represents a single line from the transcript JSONL
 type TranscriptLine struct {
-	Type    string          `json:"type"`
-	Name    string          `json:"name"`
-	Message *MessageWrapper `json:"message"`
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	IsSidechain bool            `json:"isSidechain"` // Subagent traffic
+	IsMeta      bool            `json:"isMeta"`
+	Message     *MessageWrapper `json:"message"`
 }
 
 // MessageWrapper wraps the message content array
 type MessageWrapper struct {
-	Content []ContentBlock `json:"content"`
+	Content []ContentBlock
+	Text    string        // Content given as a plain string (typed prompts)
+	Usage   *MessageUsage // Token usage of assistant messages
+}
+
+// MessageUsage is the token usage reported on an assistant message
+type MessageUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// UnmarshalJSON accepts content either as a block array or a plain string
+func (m *MessageWrapper) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Content json.RawMessage `json:"content"`
+		Usage   *MessageUsage   `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = MessageWrapper{Usage: raw.Usage}
+	content := bytes.TrimSpace(raw.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '"':
+		return json.Unmarshal(content, &m.Text)
+	}
+	return json.Unmarshal(content, &m.Content)
 }
 
 // ContentBlock represents a single content block (tool_use, text, etc.)
@@ -40,7 +73,12 @@ func ParseTranscriptLineWithTracker(data []byte, s *state.State, tracker *TaskTr
 	if err := json.Unmarshal(data, &line); err != nil {
 		return err
 	}
+	applyTranscriptLine(&line, s, tracker)
+	return nil
+}
 
+// applyTranscriptLine counts the tool uses in a decoded line
+func applyTranscriptLine(line *TranscriptLine, s *state.State, tracker *TaskTracker) {
 	if line.Message != nil && len(line.Message.Content) > 0 {
 		for _, block := range line.Message.Content {
 			if block.Type == "tool_use" {
@@ -80,11 +118,11 @@ func ParseTranscriptLineWithTracker(data []byte, s *state.State, tracker *TaskTr
 				}
 			}
 		}
-		return nil
+		return
 	}
 
 	if line.Type != "tool_use" {
-		return nil
+		return
 	}
 
 	category := CategorizeTool(line.Name)
@@ -112,8 +150,6 @@ func ParseTranscriptLineWithTracker(data []byte, s *state.State, tracker *TaskTr
 	case CategorySkill:
 		s.Tools.AppTools["Skill"]++
 	}
-
-	return nil
 }
 
 // ParseTranscript reads and parses the entire transcript file
@@ -131,19 +167,28 @@ func ParseTranscript(path string, s *state.State) error {
 		TaskIDMap: make(map[string]int),
 	}
 
+	usage := &UsageTracker{}
+
 	scanner := bufio.NewScanner(file)
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
 
 	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		// Each line is decoded once for tools, tasks and context usage
+		var line TranscriptLine
+		if err := json.Unmarshal(data, &line); err != nil {
 			continue
 		}
-		_ = ParseTranscriptLineWithTracker(line, s, tracker)
+		applyTranscriptLine(&line, s, tracker)
+		usage.observe(&line)
 	}
 
 	updateStateFromTasks(tracker, s)
+	updateStateFromUsage(usage, s)
 
 	return scanner.Err()
 }
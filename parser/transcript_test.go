@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/huyhandes/cc-hud-go/state"
@@ -203,6 +207,25 @@ func TestParseTranscriptLineInvalidJSON(t *testing.T) {
 	}
 }
 
+func TestMessageWrapperContent(t *testing.T) {
+	var line TranscriptLine
+	if err := json.Unmarshal([]byte(`{"type":"user","message":{"content":"Fix the build"}}`), &line); err != nil {
+		t.Fatalf("string content: %v", err)
+	}
+	if line.Message.Text != "Fix the build" || !isHumanPrompt(line.Message) {
+		t.Errorf("expected a typed prompt, got %+v", line.Message)
+	}
+
+	line = TranscriptLine{}
+	data := `{"type":"assistant","message":{"content":[{"type":"text"}],"usage":{"input_tokens":5,"cache_read_input_tokens":100}}}`
+	if err := json.Unmarshal([]byte(data), &line); err != nil {
+		t.Fatalf("block content: %v", err)
+	}
+	if len(line.Message.Content) != 1 || line.Message.Usage == nil || line.Message.Usage.CacheReadInputTokens != 100 {
+		t.Errorf("expected blocks and usage, got %+v", line.Message)
+	}
+}
+
 func TestParseTranscriptLineNoToolUse(t *testing.T) {
 	line := `{"type":"text","name":"test"}`
 	s := state.New()
@@ -308,3 +331,38 @@ func TestCategorizeTool_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTranscriptTurnHistory(t *testing.T) {
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"first prompt"}}`,
+		`{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","name":"Read","id":"t1"}],"usage":{"input_tokens":10,"cache_creation_input_tokens":1000,"cache_read_input_tokens":9000}}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1"}]}}`,
+		`{"type":"assistant","message":{"id":"m2","content":[{"type":"text","text":"done"}],"usage":{"input_tokens":10,"cache_creation_input_tokens":2000,"cache_read_input_tokens":10000}}}`,
+		`{"type":"user","isSidechain":true,"message":{"role":"user","content":"subagent prompt"}}`,
+		`{"type":"assistant","isSidechain":true,"message":{"id":"s1","content":[],"usage":{"input_tokens":50000}}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"text","text":"second prompt"}]}}`,
+		`{"type":"assistant","message":{"id":"m3","content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":10,"cache_creation_input_tokens":3000,"cache_read_input_tokens":12000}}}`,
+	}
+
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := state.New()
+	if err := ParseTranscript(path, s); err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	// One sample per completed user turn plus the turn in progress;
+	// tool results and subagent traffic do not start new turns
+	want := []int{12010, 15010}
+	if len(s.Context.TurnHistory) != len(want) {
+		t.Fatalf("expected turn history %v, got %v", want, s.Context.TurnHistory)
+	}
+	for i := range want {
+		if s.Context.TurnHistory[i] != want[i] {
+			t.Errorf("turn %d: expected %d tokens, got %d", i, want[i], s.Context.TurnHistory[i])
+		}
+	}
+}
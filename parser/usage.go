@@ -0,0 +1,71 @@
+package parser
+
+import "github.com/huyhandes/cc-hud-go/state"
+
+// UsageTracker records the context size at the end of every user turn
+type UsageTracker struct {
+	Samples []int
+	current int
+	pending bool
+}
+
+// observe updates the tracker from a single decoded transcript line
+func (u *UsageTracker) observe(line *TranscriptLine) {
+	if line.Message == nil {
+		return
+	}
+
+	// Subagent traffic runs in its own context window
+	if line.IsSidechain {
+		return
+	}
+
+	switch line.Type {
+	case "assistant":
+		if usage := line.Message.Usage; usage != nil {
+			tokens := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+			if tokens > 0 {
+				u.current = tokens
+				u.pending = true
+			}
+		}
+	case "user":
+		// A new human prompt closes the previous turn
+		if !line.IsMeta && isHumanPrompt(line.Message) {
+			u.flush()
+		}
+	}
+}
+
+// flush records the current context size as a completed turn
+func (u *UsageTracker) flush() {
+	if u.pending {
+		u.Samples = append(u.Samples, u.current)
+		u.pending = false
+	}
+}
+
+// isHumanPrompt reports whether user message content was typed by a person
+// rather than being a tool result fed back to the model
+func isHumanPrompt(message *MessageWrapper) bool {
+	if message.Text != "" {
+		return true
+	}
+
+	human := false
+	for _, block := range message.Content {
+		if block.Type == "tool_result" {
+			return false
+		}
+		if block.Type == "text" {
+			human = true
+		}
+	}
+	return human
+}
+
+// updateStateFromUsage copies the per-turn history into state, including the turn in progress
+func updateStateFromUsage(tracker *UsageTracker, s *state.State) {
+	tracker.flush()
+	s.Context.TurnHistory = tracker.Samples
+}
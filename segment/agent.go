@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -24,8 +25,7 @@ func (a *AgentSegment) Render(s *state.State, cfg *config.Config) (string, error
 	}
 
 	// Add agent icon
-	icon := "👤"
-	output := fmt.Sprintf("%s %s", icon, s.Agents.ActiveAgent)
+	output := icons.Label("agent", s.Agents.ActiveAgent)
 
 	// Add task description if available
 	if s.Agents.TaskDesc != "" {
@@ -34,3 +34,15 @@ func (a *AgentSegment) Render(s *state.State, cfg *config.Config) (string, error
 
 	return style.AgentStyle.Render(output), nil
 }
+
+func (a *AgentSegment) Priority() int {
+	return 30
+}
+
+// Compact leaves out the task description
+func (a *AgentSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	if s.Agents.ActiveAgent == "" {
+		return "", nil
+	}
+	return style.AgentStyle.Render(icons.Label("agent", s.Agents.ActiveAgent)), nil
+}
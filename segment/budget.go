@@ -0,0 +1,100 @@
+package segment
+
+import (
+	"fmt"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
+	"github.com/huyhandes/cc-hud-go/state"
+	"github.com/huyhandes/cc-hud-go/style"
+)
+
+// BudgetSegment displays spend across all sessions against the configured caps
+type BudgetSegment struct{}
+
+// budgetPeriod pairs a period's spend with its cap
+type budgetPeriod struct {
+	label string
+	spent float64
+	cap   float64
+}
+
+func (b *BudgetSegment) ID() string {
+	return "budget"
+}
+
+func (b *BudgetSegment) Enabled(cfg *config.Config) bool {
+	return cfg.Budget.Enabled()
+}
+
+func (b *BudgetSegment) Render(s *state.State, cfg *config.Config) (string, error) {
+	period, ok := b.tightest(s, cfg)
+	if !ok {
+		return "", nil
+	}
+
+	percentage := period.spent / period.cap * 100
+	bar := style.RenderGradientBar(percentage, 10)
+	amount := fmt.Sprintf("$%.2f/$%.2f %s", period.spent, period.cap, period.label)
+
+	// Over budget - Red with warning prefix
+	if percentage >= 100 {
+		dangerStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger).Bold(true)
+		return fmt.Sprintf("%s %s %s",
+			dangerStyle.Render(icons.Get("warning")+" "+icons.Get("budget")),
+			bar,
+			dangerStyle.Render(fmt.Sprintf("%.0f%% %s", percentage, amount)),
+		), nil
+	}
+
+	percentStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(percentage))
+	amountStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
+	return icons.Label("budget", fmt.Sprintf("%s %s %s",
+		bar,
+		percentStyle.Render(fmt.Sprintf("%.0f%%", percentage)),
+		amountStyle.Render(amount),
+	)), nil
+}
+
+func (b *BudgetSegment) Priority() int {
+	return 40
+}
+
+// Compact shows the tightest period's percentage, "💳 85%"
+func (b *BudgetSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	period, ok := b.tightest(s, cfg)
+	if !ok {
+		return "", nil
+	}
+
+	percentage := period.spent / period.cap * 100
+	if percentage >= 100 {
+		dangerStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger).Bold(true)
+		return dangerStyle.Render(fmt.Sprintf("%s %s %.0f%%", icons.Get("warning"), icons.Get("budget"), percentage)), nil
+	}
+	percentStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(percentage))
+	return icons.Label("budget", percentStyle.Render(fmt.Sprintf("%.0f%%", percentage))), nil
+}
+
+// tightest returns the configured period closest to (or furthest over) its cap
+func (b *BudgetSegment) tightest(s *state.State, cfg *config.Config) (budgetPeriod, bool) {
+	periods := []budgetPeriod{
+		{label: "today", spent: s.Budget.DailyUSD, cap: cfg.Budget.Daily},
+		{label: "week", spent: s.Budget.WeeklyUSD, cap: cfg.Budget.Weekly},
+		{label: "month", spent: s.Budget.MonthlyUSD, cap: cfg.Budget.Monthly},
+	}
+
+	var best budgetPeriod
+	found := false
+	for _, p := range periods {
+		if p.cap <= 0 {
+			continue
+		}
+		if !found || p.spent/p.cap > best.spent/best.cap {
+			best = p
+			found = true
+		}
+	}
+
+	return best, found
+}
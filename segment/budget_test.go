@@ -0,0 +1,77 @@
+package segment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+func TestBudgetSegmentDisabledWithoutCaps(t *testing.T) {
+	cfg := config.Default()
+	seg := &BudgetSegment{}
+
+	if seg.ID() != "budget" {
+		t.Errorf("expected ID 'budget', got '%s'", seg.ID())
+	}
+
+	if seg.Enabled(cfg) {
+		t.Error("expected budget segment disabled when no caps are configured")
+	}
+
+	cfg.Budget.Weekly = 50
+	if !seg.Enabled(cfg) {
+		t.Error("expected budget segment enabled when a cap is configured")
+	}
+}
+
+func TestBudgetSegmentShowsTightestPeriod(t *testing.T) {
+	cfg := config.Default()
+	cfg.Budget.Daily = 10
+	cfg.Budget.Monthly = 100
+
+	s := state.New()
+	s.Budget.DailyUSD = 2
+	s.Budget.MonthlyUSD = 60
+
+	seg := &BudgetSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "60%") {
+		t.Errorf("expected monthly percentage in output, got '%s'", output)
+	}
+	if !strings.Contains(output, "month") {
+		t.Errorf("expected period label in output, got '%s'", output)
+	}
+	if strings.Contains(output, "⚠") {
+		t.Errorf("did not expect warning under budget, got '%s'", output)
+	}
+	if !strings.Contains(output, "█") {
+		t.Errorf("expected gradient bar in output, got '%s'", output)
+	}
+}
+
+func TestBudgetSegmentExceeded(t *testing.T) {
+	cfg := config.Default()
+	cfg.Budget.Daily = 5
+
+	s := state.New()
+	s.Budget.DailyUSD = 6.5
+
+	seg := &BudgetSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "⚠") {
+		t.Errorf("expected warning prefix when over budget, got '%s'", output)
+	}
+	if !strings.Contains(output, "130%") {
+		t.Errorf("expected percentage over 100, got '%s'", output)
+	}
+}
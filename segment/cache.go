@@ -0,0 +1,74 @@
+package segment
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
+	"github.com/huyhandes/cc-hud-go/internal/models"
+	"github.com/huyhandes/cc-hud-go/state"
+	"github.com/huyhandes/cc-hud-go/style"
+)
+
+// CacheSegment displays prompt cache efficiency and estimated savings
+type CacheSegment struct{}
+
+func (c *CacheSegment) ID() string {
+	return "cache"
+}
+
+func (c *CacheSegment) Enabled(cfg *config.Config) bool {
+	return cfg.Display.Cache
+}
+
+func (c *CacheSegment) Render(s *state.State, cfg *config.Config) (string, error) {
+	if s.Context.CacheReadTokens == 0 && s.Context.CacheCreateTokens == 0 {
+		return "", nil
+	}
+
+	return icons.Label("cacheRatio", CacheHitRate(s)+" "+CacheSavings(s)), nil
+}
+
+func (c *CacheSegment) Priority() int {
+	return 20
+}
+
+// Compact shows the hit rate without the savings
+func (c *CacheSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	if s.Context.CacheReadTokens == 0 && s.Context.CacheCreateTokens == 0 {
+		return "", nil
+	}
+	return icons.Label("cacheRatio", CacheHitRate(s)), nil
+}
+
+// CacheHitRate renders the cache hit ratio, green when most input is served from cache
+func CacheHitRate(s *state.State) string {
+	rateStyle := style.GetRenderer().NewStyle().Foreground(cacheRateColor(s.Context.CacheHitRate))
+	return rateStyle.Render(fmt.Sprintf("%.0f%% hit", s.Context.CacheHitRate))
+}
+
+// CacheSavings renders the estimated dollars saved by caching at the current model's prices
+func CacheSavings(s *state.State) string {
+	saved := models.CacheSavings(s.Model.ID, s.Context.CacheReadTokens, s.Context.CacheCreateTokens)
+
+	// Cache writes outweighing reads cost more than no caching at all - Red
+	if saved < 0 {
+		lostStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger)
+		return lostStyle.Render(fmt.Sprintf("lost $%.2f", -saved))
+	}
+
+	savedStyle := style.GetRenderer().NewStyle().Foreground(style.ColorAccent)
+	return savedStyle.Render(fmt.Sprintf("saved $%.2f", saved))
+}
+
+// cacheRateColor is the inverse of ThresholdColor: a high hit rate is good
+func cacheRateColor(rate float64) lipgloss.Color {
+	if rate >= 70 {
+		return style.ColorSuccess
+	}
+	if rate >= 40 {
+		return style.ColorWarning
+	}
+	return style.ColorDanger
+}
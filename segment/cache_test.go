@@ -0,0 +1,80 @@
+package segment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+func TestCacheSegment(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Model.ID = "claude-sonnet-4-5-20250929"
+	s.Context.CacheReadTokens = 800000
+	s.Context.CacheCreateTokens = 200000
+	s.Context.CurrentInputTokens = 0
+	s.UpdateDerived()
+
+	seg := &CacheSegment{}
+
+	if seg.ID() != "cache" {
+		t.Errorf("expected ID 'cache', got '%s'", seg.ID())
+	}
+
+	if seg.Enabled(cfg) {
+		t.Error("expected standalone cache segment to be opt-in")
+	}
+
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "80% hit") {
+		t.Errorf("expected hit ratio in output, got '%s'", output)
+	}
+
+	// 800k reads save $2.16, 200k writes cost an extra $0.15
+	if !strings.Contains(output, "saved $2.01") {
+		t.Errorf("expected savings in output, got '%s'", output)
+	}
+}
+
+func TestCacheSegmentCacheBusting(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Model.ID = "claude-sonnet-4-5"
+	s.Context.CacheCreateTokens = 200000
+	s.Context.CurrentInputTokens = 10
+	s.UpdateDerived()
+
+	seg := &CacheSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "0% hit") {
+		t.Errorf("expected 0%% hit ratio, got '%s'", output)
+	}
+	if !strings.Contains(output, "lost $") {
+		t.Errorf("expected negative savings when only writing cache, got '%s'", output)
+	}
+}
+
+func TestCacheSegmentEmpty(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+
+	seg := &CacheSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if output != "" {
+		t.Errorf("expected empty output without cache activity, got '%s'", output)
+	}
+}
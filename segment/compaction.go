@@ -0,0 +1,85 @@
+package segment
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
+	"github.com/huyhandes/cc-hud-go/internal/models"
+	"github.com/huyhandes/cc-hud-go/state"
+	"github.com/huyhandes/cc-hud-go/style"
+)
+
+// CompactionSegment forecasts how many turns remain before auto-compact
+type CompactionSegment struct{}
+
+func (c *CompactionSegment) ID() string {
+	return "compaction"
+}
+
+func (c *CompactionSegment) Enabled(cfg *config.Config) bool {
+	return cfg.Display.Context
+}
+
+func (c *CompactionSegment) Render(s *state.State, cfg *config.Config) (string, error) {
+	turns, ok := c.turnsLeft(s, cfg)
+	if !ok {
+		return "", nil
+	}
+
+	if turns == 0 {
+		dangerStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger).Bold(true)
+		return dangerStyle.Render(icons.Label("compaction", "compact imminent")), nil
+	}
+
+	unit := "turns"
+	if turns == 1 {
+		unit = "turn"
+	}
+
+	turnsStyle := style.GetRenderer().NewStyle().Foreground(compactionColor(turns))
+	return turnsStyle.Render(icons.Label("compaction", fmt.Sprintf("~%d %s left", turns, unit))), nil
+}
+
+func (c *CompactionSegment) Priority() int {
+	return 50
+}
+
+// Compact shows just the number of turns left, "⏳ ~12"
+func (c *CompactionSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	turns, ok := c.turnsLeft(s, cfg)
+	if !ok {
+		return "", nil
+	}
+	if turns == 0 {
+		dangerStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger).Bold(true)
+		return dangerStyle.Render(icons.Label("compaction", "now")), nil
+	}
+	turnsStyle := style.GetRenderer().NewStyle().Foreground(compactionColor(turns))
+	return turnsStyle.Render(icons.Label("compaction", fmt.Sprintf("~%d", turns))), nil
+}
+
+// turnsLeft forecasts the turns before auto-compact, false when unknown
+func (c *CompactionSegment) turnsLeft(s *state.State, cfg *config.Config) (int, bool) {
+	if s.Context.TotalTokens == 0 {
+		return 0, false
+	}
+
+	threshold := cfg.AutocompactThreshold
+	if threshold == 0 {
+		threshold = models.Lookup(s.Model.ID, s.Model.Name).AutocompactThreshold
+	}
+	return s.Context.TurnsUntil(s.Context.TotalTokens * threshold / 100)
+}
+
+// compactionColor shifts from green to yellow to red as fewer turns remain
+func compactionColor(turns int) lipgloss.Color {
+	if turns <= 3 {
+		return style.ColorDanger
+	}
+	if turns <= 8 {
+		return style.ColorWarning
+	}
+	return style.ColorSuccess
+}
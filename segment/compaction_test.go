@@ -0,0 +1,107 @@
+package segment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+func TestCompactionSegment(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Context.TotalTokens = 200000
+	s.Context.UsedTokens = 100000
+	s.Context.TurnHistory = []int{70000, 80000, 90000, 100000}
+
+	seg := &CompactionSegment{}
+
+	if seg.ID() != "compaction" {
+		t.Errorf("expected ID 'compaction', got '%s'", seg.ID())
+	}
+
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	// Default threshold 80% of 200k = 160k, 60k remaining at 10k per turn
+	if !strings.Contains(output, "~6 turns left") {
+		t.Errorf("expected turn forecast in output, got '%s'", output)
+	}
+}
+
+func TestCompactionSegmentCustomThreshold(t *testing.T) {
+	cfg := config.Default()
+	cfg.AutocompactThreshold = 60
+	s := state.New()
+	s.Context.TotalTokens = 200000
+	s.Context.UsedTokens = 110000
+	s.Context.TurnHistory = []int{90000, 100000, 110000}
+
+	seg := &CompactionSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "~1 turn left") {
+		t.Errorf("expected single turn forecast, got '%s'", output)
+	}
+}
+
+func TestCompactionSegmentImminent(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Context.TotalTokens = 200000
+	s.Context.UsedTokens = 170000
+	s.Context.TurnHistory = []int{150000, 170000}
+
+	seg := &CompactionSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "imminent") {
+		t.Errorf("expected imminent warning past threshold, got '%s'", output)
+	}
+}
+
+func TestCompactionSegmentNoHistory(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Context.TotalTokens = 200000
+	s.Context.UsedTokens = 50000
+
+	seg := &CompactionSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if output != "" {
+		t.Errorf("expected empty output without turn history, got '%s'", output)
+	}
+}
+
+func TestCompactionSegmentUsesModelThreshold(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Model.ID = "claude-sonnet-4-5[1m]"
+	s.Context.TotalTokens = 1000000
+	s.Context.UsedTokens = 850000
+	s.Context.TurnHistory = []int{750000, 800000, 850000}
+
+	seg := &CompactionSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	// 1M models compact at 95%: 100k remaining at 50k per turn
+	if !strings.Contains(output, "~2 turns left") {
+		t.Errorf("expected forecast against 1M threshold, got '%s'", output)
+	}
+}
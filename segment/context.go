@@ -6,6 +6,7 @@ import (
 
 	"github.com/huyhandes/cc-hud-go/config"
 	"github.com/huyhandes/cc-hud-go/format"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -25,26 +26,19 @@ func (c *ContextSegment) Render(s *state.State, cfg *config.Config) (string, err
 		return "", nil
 	}
 
-	percentage := s.Context.Percentage
-
-	// Build gradient progress bar
-	bar := style.RenderGradientBar(percentage, 10)
-
-	percentageStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(percentage))
-
 	// Detailed token breakdown with semantic colors
 	details := []string{}
 
 	// Input tokens - Blue (incoming data)
 	inStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInput)
 	details = append(details,
-		fmt.Sprintf("📥 %s", inStyle.Render(format.Tokens(s.Context.TotalInputTokens))),
+		icons.Label("input", inStyle.Render(format.Tokens(s.Context.TotalInputTokens))),
 	)
 
 	// Output tokens - Emerald/Green (outgoing data)
 	outStyle := style.GetRenderer().NewStyle().Foreground(style.ColorOutput)
 	details = append(details,
-		fmt.Sprintf("📤 %s", outStyle.Render(format.Tokens(s.Context.TotalOutputTokens))),
+		icons.Label("output", outStyle.Render(format.Tokens(s.Context.TotalOutputTokens))),
 	)
 
 	// Cache stats if available - Different colors for Read vs Write
@@ -53,24 +47,43 @@ func (c *ContextSegment) Render(s *state.State, cfg *config.Config) (string, err
 		cacheWriteStyle := style.GetRenderer().NewStyle().Foreground(style.ColorCacheWrite)
 
 		details = append(details,
-			fmt.Sprintf("💾 %s%s%s",
+			icons.Label("cache", fmt.Sprintf("%s%s%s %s %s",
 				cacheReadStyle.Render("R:"+format.Tokens(s.Context.CacheReadTokens)),
 				style.GetRenderer().NewStyle().Foreground(style.ColorMuted).Render("/"),
 				cacheWriteStyle.Render("W:"+format.Tokens(s.Context.CacheCreateTokens)),
-			),
+				CacheHitRate(s),
+				CacheSavings(s),
+			)),
 		)
 	}
 
 	// Total context size - Muted gray (static constant)
 	totalStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
 	details = append(details,
-		fmt.Sprintf("⚡ %s", totalStyle.Render(format.Tokens(s.Context.TotalTokens))),
+		icons.Label("contextSize", totalStyle.Render(format.Tokens(s.Context.TotalTokens))),
 	)
 
 	// Single line format for use in custom layouts
-	return fmt.Sprintf("%s %s %s",
-		bar,
-		percentageStyle.Render(fmt.Sprintf("%.0f%%", percentage)),
-		strings.Join(details, " "),
-	), nil
+	return fmt.Sprintf("%s %s", renderContextUsage(s, 10), strings.Join(details, " ")), nil
+}
+
+func (c *ContextSegment) Priority() int {
+	return 100
+}
+
+// Compact keeps the bar and percentage, without the token breakdown
+func (c *ContextSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	if s.Context.TotalTokens == 0 {
+		return "", nil
+	}
+	return renderContextUsage(s, 5), nil
+}
+
+// renderContextUsage renders the context bar, width cells wide, and percentage
+func renderContextUsage(s *state.State, width int) string {
+	percentageStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(s.Context.Percentage))
+	return fmt.Sprintf("%s %s",
+		style.RenderGradientBar(s.Context.Percentage, width),
+		percentageStyle.Render(fmt.Sprintf("%.0f%%", s.Context.Percentage)),
+	)
 }
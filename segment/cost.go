@@ -3,6 +3,7 @@ package segment
 import (
 	"github.com/huyhandes/cc-hud-go/config"
 	"github.com/huyhandes/cc-hud-go/format"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -18,37 +19,51 @@ func (s CostSegment) Enabled(cfg *config.Config) bool {
 }
 
 func (s CostSegment) Render(st *state.State, cfg *config.Config) (string, error) {
-	if st.Cost.TotalUSD == 0 && st.Cost.DurationMs == 0 {
+	if st.Cost.TotalUSD == 0 {
 		return "", nil
 	}
 
-	parts := []string{}
+	// File changes moved to git line in multi-line layout
+	// (removed from here to avoid duplication)
 
-	if st.Cost.TotalUSD > 0 {
-		costStyle := style.GetRenderer().NewStyle().Foreground(style.ColorAccent).Bold(true)
-		parts = append(parts, costStyle.Render("💰"+format.Cost(st.Cost.TotalUSD)))
-	}
+	costStyle := style.GetRenderer().NewStyle().Foreground(style.ColorAccent).Bold(true)
+	return costStyle.Render(icons.Get("cost") + format.Cost(st.Cost.TotalUSD)), nil
+}
 
-	if st.Cost.DurationMs > 0 {
-		durationStyle := style.GetRenderer().NewStyle().Foreground(style.ColorHighlight)
-		parts = append(parts, durationStyle.Render("⏱ "+format.Duration(st.Cost.DurationMs)))
-	}
+func (s CostSegment) Priority() int {
+	return 60
+}
 
-	// File changes moved to git line in multi-line layout
-	// (removed from here to avoid duplication)
+// Compact is the full rendering, it's already short
+func (s CostSegment) Compact(st *state.State, cfg *config.Config) (string, error) {
+	return s.Render(st, cfg)
+}
 
-	if len(parts) == 0 {
+// DurationSegment displays how long the session has been running
+type DurationSegment struct{}
+
+func (d *DurationSegment) ID() string {
+	return "duration"
+}
+
+func (d *DurationSegment) Enabled(cfg *config.Config) bool {
+	return cfg.Display.Duration
+}
+
+func (d *DurationSegment) Render(s *state.State, cfg *config.Config) (string, error) {
+	if s.Cost.DurationMs == 0 {
 		return "", nil
 	}
 
-	// Join with separator for better visual separation
-	result := ""
-	for i, part := range parts {
-		if i > 0 {
-			result += "  │  "
-		}
-		result += part
-	}
+	durationStyle := style.GetRenderer().NewStyle().Foreground(style.ColorHighlight)
+	return durationStyle.Render(icons.Label("duration", format.Duration(s.Cost.DurationMs))), nil
+}
+
+func (d *DurationSegment) Priority() int {
+	return 55
+}
 
-	return result, nil
+// Compact drops the duration
+func (d *DurationSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	return "", nil
 }
@@ -0,0 +1,44 @@
+package segment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+func TestCostAndDurationSegments(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Cost.TotalUSD = 1.25
+	s.Cost.DurationMs = 125000
+
+	cost, err := CostSegment{}.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(cost, "$1.25") || strings.Contains(cost, "2m") {
+		t.Errorf("expected only the cost, got '%s'", cost)
+	}
+	if strings.Contains(cost, "│") {
+		t.Errorf("separators belong to the renderer, got '%s'", cost)
+	}
+
+	duration, err := (&DurationSegment{}).Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(duration, "2m5s") || strings.Contains(duration, "$") {
+		t.Errorf("expected only the duration, got '%s'", duration)
+	}
+
+	s.Cost.TotalUSD = 0
+	s.Cost.DurationMs = 0
+	if text, _ := (CostSegment{}).Render(s, cfg); text != "" {
+		t.Errorf("expected empty cost, got '%s'", text)
+	}
+	if text, _ := (&DurationSegment{}).Render(s, cfg); text != "" {
+		t.Errorf("expected empty duration, got '%s'", text)
+	}
+}
@@ -162,3 +162,73 @@ func TestFiveHourSegmentTimeFormatting(t *testing.T) {
 		})
 	}
 }
+
+func TestFiveHourSegmentBurnDown(t *testing.T) {
+	tests := []struct {
+		name     string
+		limitIn  time.Duration
+		resetIn  time.Duration
+		contains string
+		excludes string
+	}{
+		{"limit before reset", 48*time.Minute + 30*time.Second, 2 * time.Hour, "limit in ~48m", "✓"},
+		{"limit after reset", 3 * time.Hour, 2 * time.Hour, "✓", "limit in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			s := state.New()
+			s.RateLimits.FiveHourPercent = 60.0
+			s.RateLimits.FiveHourResetsAt = time.Now().Add(tt.resetIn).Format(time.RFC3339)
+			s.RateLimits.FiveHourLimitAt = time.Now().Add(tt.limitIn).Format(time.RFC3339)
+
+			seg := &FiveHourSegment{}
+			output, err := seg.Render(s, cfg)
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+
+			if !strings.Contains(output, tt.contains) {
+				t.Errorf("expected %q in output, got '%s'", tt.contains, output)
+			}
+			if strings.Contains(output, tt.excludes) {
+				t.Errorf("did not expect %q in output, got '%s'", tt.excludes, output)
+			}
+		})
+	}
+}
+
+func TestFiveHourSegmentInvalidResetTime(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.RateLimits.FiveHourPercent = 60.0
+	s.RateLimits.FiveHourResetsAt = "not a time"
+	s.RateLimits.FiveHourLimitAt = time.Now().Add(30 * time.Minute).Format(time.RFC3339)
+
+	seg := &FiveHourSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if strings.Contains(output, "limit in") || strings.Contains(output, "✓") {
+		t.Errorf("expected no projection with an invalid reset time, got '%s'", output)
+	}
+}
+
+func TestFiveHourSegmentNoProjection(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.RateLimits.FiveHourPercent = 60.0
+
+	seg := &FiveHourSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if strings.Contains(output, "limit in") || strings.Contains(output, "✓") {
+		t.Errorf("expected no projection without history, got '%s'", output)
+	}
+}
@@ -3,8 +3,11 @@ package segment
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/format"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -20,23 +23,79 @@ func (g *GitSegment) Enabled(cfg *config.Config) bool {
 }
 
 func (g *GitSegment) Render(s *state.State, cfg *config.Config) (string, error) {
+	return g.render(s, cfg, true), nil
+}
+
+func (g *GitSegment) Priority() int {
+	return 80
+}
+
+// Compact keeps what needs attention: the branch, an operation in progress,
+// conflicts, dirty files and ahead/behind
+func (g *GitSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	compact := *cfg
+	compact.Git = config.GitConfig{
+		ShowBranch:       cfg.Git.ShowBranch,
+		ShowOperation:    cfg.Git.ShowOperation,
+		ShowConflicts:    cfg.Git.ShowConflicts,
+		ShowDirty:        cfg.Git.ShowDirty,
+		ShowAheadBehind:  cfg.Git.ShowAheadBehind,
+		DirtyWarnMinutes: cfg.Git.DirtyWarnMinutes,
+	}
+	return g.render(s, &compact, false), nil
+}
+
+// render builds the segment, with the extra repositories when repos is set
+func (g *GitSegment) render(s *state.State, cfg *config.Config, repos bool) string {
+	// Extra repos still show when the workspace itself isn't a repository
 	if s.Git.Branch == "" {
-		return "", nil
+		if !repos {
+			return ""
+		}
+		return renderRepos(s.Git.Repos)
 	}
 
 	var parts []string
+	staleDirty := dirtySince(s, cfg, time.Now())
 
-	// Branch name with icon - Cyan (highlight color)
+	// Branch name with icon - Cyan (highlight color), Orange (warning) when
+	// changes have gone uncommitted too long; short SHA when detached
 	if cfg.Git.ShowBranch {
-		branchIcon := "🌿"
-		branchStyle := style.GetRenderer().NewStyle().Foreground(style.ColorHighlight).Bold(true)
-		parts = append(parts, branchStyle.Render(fmt.Sprintf("%s %s", branchIcon, s.Git.Branch)))
+		branchIcon := "branch"
+		branch := s.Git.Branch
+		if s.Git.Detached && s.Git.Head != "" {
+			branchIcon = "detached"
+			branch = s.Git.Head
+		}
+		branchColor := style.ColorHighlight
+		if staleDirty {
+			branchColor = style.ColorWarning
+		}
+		branchStyle := style.GetRenderer().NewStyle().Foreground(branchColor).Bold(true)
+		text := branchStyle.Render(icons.Label(branchIcon, branch))
+		// Linked worktree name - Muted, context for the branch
+		if cfg.Git.ShowWorktree && s.Git.Worktree != "" {
+			text += " " + style.GetRenderer().NewStyle().Foreground(style.ColorMuted).Render("["+s.Git.Worktree+"]")
+		}
+		parts = append(parts, text)
+	}
+
+	// Operation in progress - Peach (accent), it changes what commands do
+	if cfg.Git.ShowOperation && s.Git.Operation != "" {
+		opStyle := style.GetRenderer().NewStyle().Foreground(style.ColorAccent).Bold(true)
+		parts = append(parts, opStyle.Render(strings.ToUpper(s.Git.Operation)))
+	}
+
+	// Conflicts - Red (must be resolved)
+	if cfg.Git.ShowConflicts && s.Git.Conflicts > 0 {
+		conflictStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger).Bold(true)
+		parts = append(parts, conflictStyle.Render(fmt.Sprintf("%s%d", icons.Get("conflicts"), s.Git.Conflicts)))
 	}
 
 	// Dirty indicator with warning icon - Orange (warning)
 	if cfg.Git.ShowDirty && s.Git.DirtyFiles > 0 {
 		dirtyStyle := style.GetRenderer().NewStyle().Foreground(style.ColorWarning)
-		parts = append(parts, dirtyStyle.Render(fmt.Sprintf("⚠%d", s.Git.DirtyFiles)))
+		parts = append(parts, dirtyStyle.Render(fmt.Sprintf("%s%d", icons.Get("warning"), s.Git.DirtyFiles)))
 	}
 
 	// Ahead/behind with colored arrows
@@ -44,12 +103,12 @@ func (g *GitSegment) Render(s *state.State, cfg *config.Config) (string, error)
 		if s.Git.Ahead > 0 {
 			// Ahead - Emerald/Green (good, pushing forward)
 			aheadStyle := style.GetRenderer().NewStyle().Foreground(style.ColorSuccess)
-			parts = append(parts, aheadStyle.Render(fmt.Sprintf("↑%d", s.Git.Ahead)))
+			parts = append(parts, aheadStyle.Render(fmt.Sprintf("%s%d", icons.Get("ahead"), s.Git.Ahead)))
 		}
 		if s.Git.Behind > 0 {
 			// Behind - Red (needs attention)
 			behindStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger)
-			parts = append(parts, behindStyle.Render(fmt.Sprintf("↓%d", s.Git.Behind)))
+			parts = append(parts, behindStyle.Render(fmt.Sprintf("%s%d", icons.Get("behind"), s.Git.Behind)))
 		}
 	}
 
@@ -70,7 +129,159 @@ func (g *GitSegment) Render(s *state.State, cfg *config.Config) (string, error)
 			delStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger)
 			parts = append(parts, delStyle.Render(fmt.Sprintf("-%d", s.Git.Deleted)))
 		}
+		if s.Git.Renamed > 0 {
+			// Renamed - Teal, like modifications
+			renStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInfo)
+			parts = append(parts, renStyle.Render(fmt.Sprintf("%s%d", icons.Get("renamed"), s.Git.Renamed)))
+		}
+	}
+
+	// Lines changed in the working tree, with the staged share when only part is staged
+	if cfg.Git.ShowLineStats && !s.Git.Total.IsZero() {
+		mutedStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
+		text := mutedStyle.Render(icons.Get("lines")) + renderLineStats(s.Git.Total)
+		if !s.Git.Staged.IsZero() && !s.Git.Unstaged.IsZero() {
+			text += " " + mutedStyle.Render(fmt.Sprintf("(+%d/-%d staged)", s.Git.Staged.Added, s.Git.Staged.Removed))
+		}
+		parts = append(parts, text)
+	}
+
+	// Commits and lines since the base branch - review size
+	if cfg.Git.ShowBase && s.Git.Base != "" && (s.Git.BaseCommits > 0 || !s.Git.BaseLines.IsZero()) {
+		mutedStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
+		commitStyle := style.GetRenderer().NewStyle().Foreground(style.ColorPrimary)
+		commits := fmt.Sprintf("%d commits", s.Git.BaseCommits)
+		if s.Git.BaseCommits == 1 {
+			commits = "1 commit"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s",
+			mutedStyle.Render("vs "+s.Git.Base+":"),
+			commitStyle.Render(commits),
+			renderLineStats(s.Git.BaseLines),
+		))
+	}
+
+	// Commits created this session - Lavender (primary), what actually landed
+	if cfg.Git.ShowSessionCommits && len(s.Git.SessionCommits) > 0 {
+		commitStyle := style.GetRenderer().NewStyle().Foreground(style.ColorPrimary)
+		commits := fmt.Sprintf("%d commits this session", len(s.Git.SessionCommits))
+		if len(s.Git.SessionCommits) == 1 {
+			commits = "1 commit this session"
+		}
+		text := commitStyle.Render(commits)
+		if lines := s.Git.SessionLines(); !lines.IsZero() {
+			text += " " + renderLineStats(lines)
+		}
+		parts = append(parts, text)
+	}
+
+	// Untracked - Muted (not part of the repo yet)
+	if cfg.Git.ShowUntracked && s.Git.Untracked > 0 {
+		untrackedStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
+		parts = append(parts, untrackedStyle.Render(fmt.Sprintf("?%d", s.Git.Untracked)))
+	}
+
+	// Stash entries - Muted (parked work)
+	if cfg.Git.ShowStash && s.Git.Stashes > 0 {
+		stashStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
+		parts = append(parts, stashStyle.Render(fmt.Sprintf("%s%d", icons.Get("stash"), s.Git.Stashes)))
+	}
+
+	// Nearest tag - Lavender (primary)
+	if cfg.Git.ShowTag && s.Git.Tag != "" {
+		tagStyle := style.GetRenderer().NewStyle().Foreground(style.ColorPrimary)
+		parts = append(parts, tagStyle.Render(icons.Label("tag", s.Git.Tag)))
+	}
+
+	// Last commit age, author and subject - Muted, age Orange when dirty too long
+	if cfg.Git.ShowLastCommit && s.Git.LastCommitTime != nil {
+		parts = append(parts, renderLastCommit(s, cfg, staleDirty))
+	}
+
+	// Extra repositories - set apart by a wider gap, they aren't the workspace.
+	// Separators between segments are the renderer's, so none is drawn here.
+	text := strings.Join(parts, " ")
+	if extra := renderRepos(s.Git.Repos); repos && extra != "" {
+		if text != "" {
+			text += "  "
+		}
+		text += extra
+	}
+	return text
+}
+
+// dirtySince reports whether the working tree has been dirty for longer than
+// the configured warning threshold, measured from the last commit
+func dirtySince(s *state.State, cfg *config.Config, now time.Time) bool {
+	if cfg.Git.DirtyWarnMinutes <= 0 || s.Git.DirtyFiles == 0 || s.Git.LastCommitTime == nil {
+		return false
+	}
+	return now.Sub(*s.Git.LastCommitTime) > time.Duration(cfg.Git.DirtyWarnMinutes)*time.Minute
+}
+
+// renderLastCommit renders "🕓 3h ago · Ada · Fix the login redirect…"
+func renderLastCommit(s *state.State, cfg *config.Config, staleDirty bool) string {
+	mutedStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
+	ageStyle := mutedStyle
+	if staleDirty {
+		ageStyle = style.GetRenderer().NewStyle().Foreground(style.ColorWarning)
+	}
+
+	text := ageStyle.Render(icons.Label("lastCommit", format.Age(time.Since(*s.Git.LastCommitTime))))
+	if cfg.Git.ShowCommitAuthor && s.Git.LastCommitAuthor != "" {
+		text += mutedStyle.Render(" · " + s.Git.LastCommitAuthor)
+	}
+	if cfg.Git.CommitSubjectLength > 0 && s.Git.LastCommitSubject != "" {
+		text += mutedStyle.Render(" · " + truncateRunes(s.Git.LastCommitSubject, cfg.Git.CommitSubjectLength))
+	}
+	return text
+}
+
+// truncateRunes shortens s to at most n characters, ending with "…" when cut
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// renderRepos renders extra repositories compactly as "name:branch ⚠N",
+// separated by muted bars; timed out repos are muted entirely
+func renderRepos(repos []state.RepoInfo) string {
+	mutedStyle := style.GetRenderer().NewStyle().Foreground(style.ColorMuted)
+	branchStyle := style.GetRenderer().NewStyle().Foreground(style.ColorHighlight)
+	dirtyStyle := style.GetRenderer().NewStyle().Foreground(style.ColorWarning)
+
+	var parts []string
+	for _, repo := range repos {
+		if repo.TimedOut {
+			branch := repo.Branch
+			if branch == "" {
+				branch = "…" // Timed out before the first successful read
+			}
+			parts = append(parts, mutedStyle.Render(repo.Name+":"+branch))
+			continue
+		}
+
+		text := mutedStyle.Render(repo.Name+":") + branchStyle.Render(repo.Branch)
+		if repo.DirtyFiles > 0 {
+			text += " " + dirtyStyle.Render(fmt.Sprintf("%s%d", icons.Get("warning"), repo.DirtyFiles))
+		}
+		parts = append(parts, text)
 	}
 
-	return strings.Join(parts, " "), nil
+	return strings.Join(parts, "  ")
+}
+
+// renderLineStats renders "+added/-removed" in green and red
+func renderLineStats(l state.LineStats) string {
+	addStyle := style.GetRenderer().NewStyle().Foreground(style.ColorSuccess)
+	removeStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger)
+	return addStyle.Render(fmt.Sprintf("+%d", l.Added)) +
+		style.GetRenderer().NewStyle().Foreground(style.ColorMuted).Render("/") +
+		removeStyle.Render(fmt.Sprintf("-%d", l.Removed))
 }
@@ -3,6 +3,7 @@ package segment
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/huyhandes/cc-hud-go/config"
 	"github.com/huyhandes/cc-hud-go/state"
@@ -51,3 +52,267 @@ func TestGitSegmentNoBranch(t *testing.T) {
 		t.Errorf("expected empty output with no branch, got '%s'", output)
 	}
 }
+
+func TestGitSegmentDetachedShowsSHA(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Git.Branch = "HEAD"
+	s.Git.Detached = true
+	s.Git.Head = "4f2a9c1"
+
+	output, err := (&GitSegment{}).Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "4f2a9c1") {
+		t.Errorf("expected short SHA when detached, got '%s'", output)
+	}
+	if strings.Contains(output, "HEAD") {
+		t.Errorf("did not expect literal HEAD when detached, got '%s'", output)
+	}
+}
+
+func TestGitSegmentRepoState(t *testing.T) {
+	cfg := config.Default()
+	cfg.Git.ShowTag = true
+	s := state.New()
+	s.Git.Branch = "main"
+	s.Git.Operation = "rebase"
+	s.Git.Conflicts = 2
+	s.Git.Untracked = 4
+	s.Git.Stashes = 1
+	s.Git.Renamed = 3
+	s.Git.Tag = "v1.2.0"
+
+	seg := &GitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	for _, want := range []string{"REBASE", "✖2", "?4", "≡1", "»3", "v1.2.0"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got '%s'", want, output)
+		}
+	}
+
+	// Each toggle hides its part
+	cfg.Git.ShowOperation = false
+	cfg.Git.ShowConflicts = false
+	cfg.Git.ShowUntracked = false
+	cfg.Git.ShowStash = false
+	cfg.Git.ShowTag = false
+	output, err = seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	for _, unwanted := range []string{"REBASE", "✖2", "?4", "≡1", "v1.2.0"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("did not expect %q with toggle off, got '%s'", unwanted, output)
+		}
+	}
+}
+
+func TestGitSegmentLineStats(t *testing.T) {
+	cfg := config.Default()
+	cfg.Git.ShowBase = true
+	s := state.New()
+	s.Git.Branch = "feature"
+	s.Git.Total = state.LineStats{Added: 40, Removed: 12}
+	s.Git.Staged = state.LineStats{Added: 10, Removed: 2}
+	s.Git.Unstaged = state.LineStats{Added: 30, Removed: 10}
+	s.Git.Base = "main"
+	s.Git.BaseCommits = 3
+	s.Git.BaseLines = state.LineStats{Added: 200, Removed: 50}
+
+	seg := &GitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	for _, want := range []string{"Δ", "+40", "-12", "(+10/-2 staged)", "vs main:", "3 commits", "+200", "-50"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got '%s'", want, output)
+		}
+	}
+
+	// Fully staged changes need no breakdown; a single commit is singular
+	s.Git.Unstaged = state.LineStats{}
+	s.Git.BaseCommits = 1
+	output, _ = seg.Render(s, cfg)
+	if strings.Contains(output, "staged") {
+		t.Errorf("did not expect staged breakdown, got '%s'", output)
+	}
+	if !strings.Contains(output, "1 commit ") {
+		t.Errorf("expected singular commit, got '%s'", output)
+	}
+
+	cfg.Git.ShowLineStats = false
+	cfg.Git.ShowBase = false
+	output, _ = seg.Render(s, cfg)
+	for _, unwanted := range []string{"Δ", "vs main"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("did not expect %q with toggle off, got '%s'", unwanted, output)
+		}
+	}
+}
+
+func TestGitSegmentWorktreeAndRepos(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Git.Branch = "feature"
+	s.Git.Worktree = "feature-wt"
+	s.Git.Repos = []state.RepoInfo{
+		{Name: "api", Branch: "main", DirtyFiles: 3},
+		{Name: "web", Branch: "develop"},
+		{Name: "slow", TimedOut: true},
+	}
+
+	seg := &GitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	for _, want := range []string{"feature", "[feature-wt]", "api:", "main", "⚠3", "web:", "develop", "slow:…"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got '%s'", want, output)
+		}
+	}
+	if strings.Index(output, "feature-wt") > strings.Index(output, "api:") {
+		t.Errorf("expected worktree next to the branch, before extra repos, got '%s'", output)
+	}
+
+	cfg.Git.ShowWorktree = false
+	output, _ = seg.Render(s, cfg)
+	if strings.Contains(output, "feature-wt") {
+		t.Errorf("did not expect worktree with toggle off, got '%s'", output)
+	}
+
+	// Extra repos render even outside a repository
+	s.Git.Branch = ""
+	output, _ = seg.Render(s, cfg)
+	if !strings.HasPrefix(output, "api:") {
+		t.Errorf("expected extra repos without a leading gap, got '%s'", output)
+	}
+}
+
+func TestGitSegmentSessionCommits(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.Git.Branch = "main"
+	s.Git.SessionCommits = []state.SessionCommit{
+		{ID: "b", Subject: "Trim", Lines: state.LineStats{Removed: 4}},
+		{ID: "a", Subject: "Add", Lines: state.LineStats{Added: 20, Removed: 1}},
+	}
+
+	seg := &GitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	for _, want := range []string{"2 commits this session", "+20", "-5"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got '%s'", want, output)
+		}
+	}
+
+	s.Git.SessionCommits = s.Git.SessionCommits[:1]
+	output, _ = seg.Render(s, cfg)
+	if !strings.Contains(output, "1 commit this session") {
+		t.Errorf("expected singular commit, got '%s'", output)
+	}
+
+	cfg.Git.ShowSessionCommits = false
+	output, _ = seg.Render(s, cfg)
+	if strings.Contains(output, "this session") {
+		t.Errorf("did not expect session commits with toggle off, got '%s'", output)
+	}
+}
+
+func TestGitSegmentLastCommit(t *testing.T) {
+	cfg := config.Default()
+	cfg.Git.ShowLastCommit = true
+	cfg.Git.CommitSubjectLength = 12
+	s := state.New()
+	s.Git.Branch = "main"
+	committed := time.Now().Add(-3 * time.Hour)
+	s.Git.LastCommitTime = &committed
+	s.Git.LastCommitAuthor = "Ada"
+	s.Git.LastCommitSubject = "Fix the login redirect loop"
+
+	seg := &GitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	for _, want := range []string{"3h ago", "Ada", "Fix the log…"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got '%s'", want, output)
+		}
+	}
+	if strings.Contains(output, "redirect") {
+		t.Errorf("expected subject to be truncated, got '%s'", output)
+	}
+
+	cfg.Git.ShowCommitAuthor = false
+	cfg.Git.CommitSubjectLength = 0
+	output, _ = seg.Render(s, cfg)
+	if strings.Contains(output, "Ada") || strings.Contains(output, "Fix") {
+		t.Errorf("expected only the age, got '%s'", output)
+	}
+
+	cfg.Git.ShowLastCommit = false
+	output, _ = seg.Render(s, cfg)
+	if strings.Contains(output, "ago") {
+		t.Errorf("did not expect last commit with toggle off, got '%s'", output)
+	}
+}
+
+func TestDirtySince(t *testing.T) {
+	cfg := config.Default()
+	now := time.Now()
+	s := state.New()
+	committed := now.Add(-2 * time.Hour)
+	s.Git.LastCommitTime = &committed
+
+	if dirtySince(s, cfg, now) {
+		t.Error("a clean tree should never warn")
+	}
+
+	s.Git.DirtyFiles = 3
+	if !dirtySince(s, cfg, now) {
+		t.Error("expected warning when dirty for over an hour since the last commit")
+	}
+
+	recent := now.Add(-30 * time.Minute)
+	s.Git.LastCommitTime = &recent
+	if dirtySince(s, cfg, now) {
+		t.Error("did not expect warning within the threshold")
+	}
+
+	s.Git.LastCommitTime = &committed
+	cfg.Git.DirtyWarnMinutes = 0
+	if dirtySince(s, cfg, now) {
+		t.Error("did not expect warning when disabled")
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"truncated", 5, "trun…"},
+		{"héllo wörld", 6, "héllo…"},
+		{"ab", 1, "a"},
+	}
+	for _, tt := range tests {
+		if got := truncateRunes(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
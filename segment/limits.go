@@ -0,0 +1,109 @@
+package segment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
+	"github.com/huyhandes/cc-hud-go/state"
+	"github.com/huyhandes/cc-hud-go/style"
+)
+
+// LimitsSegment lists additional OAuth rate limit buckets (per-model weekly
+// limits, extra usage) once they cross the configured threshold
+type LimitsSegment struct{}
+
+// primaryBuckets are already rendered by FiveHourSegment and RateLimitSegment
+var primaryBuckets = map[string]bool{
+	"five_hour": true,
+	"seven_day": true,
+}
+
+func (l *LimitsSegment) ID() string {
+	return "limits"
+}
+
+func (l *LimitsSegment) Enabled(cfg *config.Config) bool {
+	return cfg.Display.RateLimits
+}
+
+func (l *LimitsSegment) Render(s *state.State, cfg *config.Config) (string, error) {
+	threshold := float64(cfg.LimitsThreshold)
+
+	names := make([]string, 0, len(s.RateLimits.Buckets))
+	for name, bucket := range s.RateLimits.Buckets {
+		if primaryBuckets[name] || bucket.Percent < threshold {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		bucket := s.RateLimits.Buckets[name]
+		percentStyle := style.GetRenderer().NewStyle().Foreground(limitColor(s, bucket.Percent))
+		parts = append(parts, fmt.Sprintf("%s %s%s",
+			bucketLabel(name),
+			percentStyle.Render(fmt.Sprintf("%.0f%%", bucket.Percent)),
+			resetCountdown(bucket.ResetsAt),
+		))
+	}
+
+	if extra := s.RateLimits.ExtraUsage; extra.Enabled && extra.Percent >= threshold {
+		percentStyle := style.GetRenderer().NewStyle().Foreground(limitColor(s, extra.Percent))
+		parts = append(parts, fmt.Sprintf("extra %s", percentStyle.Render(fmt.Sprintf("%.0f%%", extra.Percent))))
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	separator := style.GetRenderer().NewStyle().Foreground(style.ColorMuted).Render(" · ")
+	return icons.Label("limits", strings.Join(parts, separator)), nil
+}
+
+func (l *LimitsSegment) Priority() int {
+	return 35
+}
+
+// Compact shows only the highest bucket, without its reset countdown
+func (l *LimitsSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	threshold := float64(cfg.LimitsThreshold)
+
+	// Ties go to the first bucket name, so the choice doesn't follow map order
+	winner, highest := "", -1.0
+	for name, bucket := range s.RateLimits.Buckets {
+		if primaryBuckets[name] || bucket.Percent < threshold {
+			continue
+		}
+		if bucket.Percent > highest || (bucket.Percent == highest && name < winner) {
+			winner, highest = name, bucket.Percent
+		}
+	}
+	label := bucketLabel(winner)
+	if extra := s.RateLimits.ExtraUsage; extra.Enabled && extra.Percent >= threshold && extra.Percent > highest {
+		label, highest = "extra", extra.Percent
+	}
+
+	if highest < 0 {
+		return "", nil
+	}
+	percentStyle := style.GetRenderer().NewStyle().Foreground(limitColor(s, highest))
+	return icons.Label("limits", label+" "+percentStyle.Render(fmt.Sprintf("%.0f%%", highest))), nil
+}
+
+// bucketLabel turns an API bucket name into a short label
+// (e.g. "seven_day_opus" → "7d opus", "five_hour" → "5h")
+func bucketLabel(name string) string {
+	label := name
+	switch {
+	case strings.HasPrefix(label, "seven_day"):
+		label = "7d" + strings.TrimPrefix(label, "seven_day")
+	case strings.HasPrefix(label, "five_hour"):
+		label = "5h" + strings.TrimPrefix(label, "five_hour")
+	}
+	return strings.ReplaceAll(label, "_", " ")
+}
@@ -0,0 +1,104 @@
+package segment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/state"
+)
+
+func TestLimitsSegment(t *testing.T) {
+	cfg := config.Default()
+	cfg.LimitsThreshold = 50
+
+	s := state.New()
+	s.RateLimits.Buckets = map[string]state.RateLimitBucket{
+		"five_hour":            {Percent: 95},
+		"seven_day":            {Percent: 90},
+		"seven_day_opus":       {Percent: 85},
+		"seven_day_sonnet":     {Percent: 20},
+		"seven_day_oauth_apps": {Percent: 50},
+	}
+	s.RateLimits.ExtraUsage = state.ExtraUsageInfo{Enabled: true, Percent: 75}
+
+	seg := &LimitsSegment{}
+
+	if seg.ID() != "limits" {
+		t.Errorf("expected ID 'limits', got '%s'", seg.ID())
+	}
+
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	for _, want := range []string{"7d opus", "85%", "7d oauth apps", "extra", "75%"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got '%s'", want, output)
+		}
+	}
+
+	// Below threshold and primary buckets are not repeated
+	for _, unwanted := range []string{"sonnet", "95%", "90%"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("did not expect %q in output, got '%s'", unwanted, output)
+		}
+	}
+}
+
+func TestLimitsSegmentEmpty(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.RateLimits.Buckets = map[string]state.RateLimitBucket{
+		"seven_day_opus": {Percent: 10},
+	}
+
+	seg := &LimitsSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if output != "" {
+		t.Errorf("expected empty output when all buckets are below threshold, got '%s'", output)
+	}
+}
+
+func TestLimitsSegmentCompactTie(t *testing.T) {
+	cfg := config.Default()
+	cfg.LimitsThreshold = 50
+
+	s := state.New()
+	s.RateLimits.Buckets = map[string]state.RateLimitBucket{
+		"seven_day_sonnet": {Percent: 80},
+		"seven_day_opus":   {Percent: 80},
+		"seven_day_cowork": {Percent: 80},
+	}
+
+	// Equal percentages pick the first bucket name on every render
+	for range 20 {
+		output, err := (&LimitsSegment{}).Compact(s, cfg)
+		if err != nil {
+			t.Fatalf("compact failed: %v", err)
+		}
+		if !strings.Contains(output, "7d cowork") {
+			t.Fatalf("expected the first bucket by name, got '%s'", output)
+		}
+	}
+}
+
+func TestBucketLabel(t *testing.T) {
+	tests := map[string]string{
+		"seven_day_opus":       "7d opus",
+		"seven_day_oauth_apps": "7d oauth apps",
+		"five_hour":            "5h",
+		"iguana_necktie":       "iguana necktie",
+	}
+
+	for name, want := range tests {
+		if got := bucketLabel(name); got != want {
+			t.Errorf("bucketLabel(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
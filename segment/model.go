@@ -1,9 +1,8 @@
 package segment
 
 import (
-	"fmt"
-
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -24,5 +23,17 @@ func (m *ModelSegment) Render(s *state.State, cfg *config.Config) (string, error
 	}
 
 	model := style.ModelStyle.Render(s.Model.Name)
-	return fmt.Sprintf("🤖 %s", model), nil
+	return icons.Label("model", model), nil
+}
+
+func (m *ModelSegment) Priority() int {
+	return 90
+}
+
+// Compact drops the icon
+func (m *ModelSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	if s.Model.Name == "" {
+		return "", nil
+	}
+	return style.ModelStyle.Render(s.Model.Name), nil
 }
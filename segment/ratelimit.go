@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/format"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -23,22 +26,46 @@ func (r *RateLimitSegment) Render(s *state.State, cfg *config.Config) (string, e
 	// This segment now only renders 7d limit
 	// 5h limit is rendered separately by FiveHourSegment
 
-	// Prefer OAuth API data (more accurate)
-	if s.RateLimits.SevenDayPercent > 0 {
-		bar7d := style.RenderGradientBar(s.RateLimits.SevenDayPercent, 10)
-		percentStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(s.RateLimits.SevenDayPercent))
-		return fmt.Sprintf("📊 %s %s", bar7d, percentStyle.Render(fmt.Sprintf("%.0f%%", s.RateLimits.SevenDayPercent))), nil
+	percentage, timeInfo, ok := r.usage(s)
+	if !ok {
+		return "", nil
 	}
 
-	// Fallback to stdin data (if provided)
-	if s.RateLimits.SevenDayTotal == 0 {
+	// Threshold mode: stay quiet until usage needs attention
+	if cfg.SevenDayMode == "threshold" && percentage < float64(cfg.SevenDayThreshold) {
 		return "", nil
 	}
 
-	sevenDayPercentage := float64(s.RateLimits.SevenDayUsed) / float64(s.RateLimits.SevenDayTotal) * 100.0
-	bar := style.RenderGradientBar(sevenDayPercentage, 10)
-	percentStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(sevenDayPercentage))
-	return fmt.Sprintf("📊 %s %s", bar, percentStyle.Render(fmt.Sprintf("%.0f%%", sevenDayPercentage))), nil
+	bar := limitBar(s, percentage)
+	percentStyle := style.GetRenderer().NewStyle().Foreground(limitColor(s, percentage))
+	return icons.Label("sevenDay", fmt.Sprintf("%s %s%s", bar, percentStyle.Render(fmt.Sprintf("%.0f%%", percentage)), timeInfo)), nil
+}
+
+func (r *RateLimitSegment) Priority() int {
+	return 45
+}
+
+// Compact shows the 7d percentage without the bar or reset countdown
+func (r *RateLimitSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	percentage, _, ok := r.usage(s)
+	if !ok || (cfg.SevenDayMode == "threshold" && percentage < float64(cfg.SevenDayThreshold)) {
+		return "", nil
+	}
+	percentStyle := style.GetRenderer().NewStyle().Foreground(limitColor(s, percentage))
+	return icons.Label("sevenDay", percentStyle.Render(fmt.Sprintf("%.0f%%", percentage))), nil
+}
+
+// usage returns the 7d percentage and reset countdown, false without data
+func (r *RateLimitSegment) usage(s *state.State) (float64, string, bool) {
+	// Prefer OAuth API data (more accurate); 0% is valid once OAuth has answered
+	if s.RateLimits.FromOAuth || s.RateLimits.SevenDayPercent > 0 {
+		return s.RateLimits.SevenDayPercent, resetCountdown(s.RateLimits.SevenDayResetsAt), true
+	}
+	// Fallback to stdin data (if provided)
+	if s.RateLimits.SevenDayTotal > 0 {
+		return float64(s.RateLimits.SevenDayUsed) / float64(s.RateLimits.SevenDayTotal) * 100.0, "", true
+	}
+	return 0, "", false
 }
 
 // FiveHourSegment displays 5-hour rate limit with elapsed time
@@ -54,33 +81,115 @@ func (f *FiveHourSegment) Enabled(cfg *config.Config) bool {
 
 func (f *FiveHourSegment) Render(s *state.State, cfg *config.Config) (string, error) {
 	// Only render if OAuth data available
-	if s.RateLimits.FiveHourPercent <= 0 {
+	if !s.RateLimits.FromOAuth && s.RateLimits.FiveHourPercent <= 0 {
 		return "", nil
 	}
 
-	bar5h := style.RenderGradientBar(s.RateLimits.FiveHourPercent, 10)
-	percentStyle := style.GetRenderer().NewStyle().Foreground(style.ThresholdColor(s.RateLimits.FiveHourPercent))
+	bar5h := limitBar(s, s.RateLimits.FiveHourPercent)
+	percentStyle := style.GetRenderer().NewStyle().Foreground(limitColor(s, s.RateLimits.FiveHourPercent))
 
 	// Calculate time remaining in 5h window
-	timeInfo := ""
-	if s.RateLimits.FiveHourResetsAt != "" {
-		if resetTime, err := time.Parse(time.RFC3339, s.RateLimits.FiveHourResetsAt); err == nil {
-			now := time.Now()
-			if resetTime.After(now) {
-				remaining := resetTime.Sub(now)
-
-				// Format remaining time
-				hours := int(remaining.Hours())
-				minutes := int(remaining.Minutes()) % 60
-
-				if hours > 0 {
-					timeInfo = fmt.Sprintf(" (%dh%dm)", hours, minutes)
-				} else {
-					timeInfo = fmt.Sprintf(" (%dm)", minutes)
-				}
-			}
-		}
-	}
-
-	return fmt.Sprintf("⏱️ %s %s%s", bar5h, percentStyle.Render(fmt.Sprintf("%.0f%%", s.RateLimits.FiveHourPercent)), timeInfo), nil
+	timeInfo := resetCountdown(s.RateLimits.FiveHourResetsAt)
+
+	return icons.Label("fiveHour", fmt.Sprintf("%s %s%s%s%s", bar5h, percentStyle.Render(fmt.Sprintf("%.0f%%", s.RateLimits.FiveHourPercent)), timeInfo, burnDown(s), offlineMarker(s))), nil
+}
+
+func (f *FiveHourSegment) Priority() int {
+	return 70
+}
+
+// Compact shows the 5h percentage, keeping the burn-down warning only when
+// the limit will be hit
+func (f *FiveHourSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	if !s.RateLimits.FromOAuth && s.RateLimits.FiveHourPercent <= 0 {
+		return "", nil
+	}
+
+	percentStyle := style.GetRenderer().NewStyle().Foreground(limitColor(s, s.RateLimits.FiveHourPercent))
+	text := icons.Label("fiveHour", percentStyle.Render(fmt.Sprintf("%.0f%%", s.RateLimits.FiveHourPercent)))
+	if _, hit, ok := limitForecast(s); ok && hit {
+		text += burnDown(s)
+	}
+	return text, nil
+}
+
+// offlineMarker flags cached values shown while offline
+func offlineMarker(s *state.State) string {
+	if !s.RateLimits.Offline {
+		return ""
+	}
+	return " " + style.GetRenderer().NewStyle().Foreground(style.ColorMuted).Italic(true).Render("offline")
+}
+
+// burnDown renders whether the 5h limit will be hit before the window resets
+// at the recent burn rate: " limit in ~48m" when it will, " ✓" when it won't
+func burnDown(s *state.State) string {
+	limitAt, hit, ok := limitForecast(s)
+	if !ok {
+		return ""
+	}
+
+	if !hit {
+		safeStyle := style.GetRenderer().NewStyle().Foreground(style.ColorSuccess)
+		return " " + safeStyle.Render(icons.Get("onTrack"))
+	}
+
+	limitStyle := style.GetRenderer().NewStyle().Foreground(style.ColorDanger).Bold(true)
+	return " " + limitStyle.Render("limit in ~"+format.Countdown(time.Until(limitAt)))
+}
+
+// limitForecast returns when the 5h limit will be reached at the recent burn
+// rate and whether that is before the window resets; false without a forecast
+func limitForecast(s *state.State) (time.Time, bool, bool) {
+	if s.RateLimits.FiveHourLimitAt == "" {
+		return time.Time{}, false, false
+	}
+
+	limitAt, err := time.Parse(time.RFC3339, s.RateLimits.FiveHourLimitAt)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+
+	// Without a valid reset time there's nothing to compare against
+	resetTime, err := time.Parse(time.RFC3339, s.RateLimits.FiveHourResetsAt)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+	return limitAt, limitAt.Before(resetTime), true
+}
+
+// limitBar renders a utilization bar, muted when the OAuth data is stale
+func limitBar(s *state.State, percentage float64) string {
+	if s.RateLimits.Stale {
+		return style.RenderMutedBar(percentage, 10)
+	}
+	return style.RenderGradientBar(percentage, 10)
+}
+
+// limitColor colors a utilization percentage, muted when the OAuth data is stale
+func limitColor(s *state.State, percentage float64) lipgloss.Color {
+	if s.RateLimits.Stale {
+		return style.ColorMuted
+	}
+	return style.ThresholdColor(percentage)
+}
+
+// resetCountdown formats the time until an ISO 8601 reset timestamp as " (2d5h)"
+// Returns an empty string when the timestamp is missing, invalid or in the past
+func resetCountdown(resetsAt string) string {
+	if resetsAt == "" {
+		return ""
+	}
+
+	resetTime, err := time.Parse(time.RFC3339, resetsAt)
+	if err != nil {
+		return ""
+	}
+
+	remaining := time.Until(resetTime)
+	if remaining <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%s)", format.Countdown(remaining))
 }
@@ -3,9 +3,11 @@ package segment
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/huyhandes/cc-hud-go/config"
 	"github.com/huyhandes/cc-hud-go/state"
+	"github.com/huyhandes/cc-hud-go/style"
 )
 
 func TestRateLimitSegment(t *testing.T) {
@@ -86,3 +88,109 @@ func TestRateLimitUsesGradientBar(t *testing.T) {
 		t.Error("Expected gradient bar characters in rate limit segment")
 	}
 }
+
+func TestRateLimitSegmentResetCountdown(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.RateLimits.FromOAuth = true
+	s.RateLimits.SevenDayPercent = 42
+	s.RateLimits.SevenDayResetsAt = time.Now().Add(3*24*time.Hour + 5*time.Hour + 30*time.Minute).Format(time.RFC3339)
+
+	seg := &RateLimitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "(3d5h)") {
+		t.Errorf("expected days/hours countdown in output, got '%s'", output)
+	}
+}
+
+func TestRateLimitSegmentZeroPercentFromOAuth(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.RateLimits.FromOAuth = true
+	s.RateLimits.SevenDayPercent = 0
+
+	seg := &RateLimitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, "0%") {
+		t.Errorf("expected 0%% bar when OAuth reports no usage, got '%s'", output)
+	}
+}
+
+func TestRateLimitSegmentThresholdMode(t *testing.T) {
+	cfg := config.Default()
+	cfg.SevenDayMode = "threshold"
+	cfg.SevenDayThreshold = 80
+
+	s := state.New()
+	s.RateLimits.FromOAuth = true
+	s.RateLimits.SevenDayPercent = 79
+
+	seg := &RateLimitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if output != "" {
+		t.Errorf("expected hidden bar below threshold, got '%s'", output)
+	}
+
+	s.RateLimits.SevenDayPercent = 80
+	output, err = seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(output, "80%") {
+		t.Errorf("expected bar at threshold, got '%s'", output)
+	}
+}
+
+func TestRateLimitSegmentStaleIsMuted(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.RateLimits.FromOAuth = true
+	s.RateLimits.Stale = true
+	s.RateLimits.SevenDayPercent = 40
+
+	seg := &RateLimitSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if !strings.Contains(output, style.RenderMutedBar(40, 10)) {
+		t.Errorf("expected muted bar for stale data, got '%s'", output)
+	}
+}
+
+func TestFiveHourSegmentOfflineMarker(t *testing.T) {
+	cfg := config.Default()
+	s := state.New()
+	s.RateLimits.FromOAuth = true
+	s.RateLimits.FiveHourPercent = 30
+
+	seg := &FiveHourSegment{}
+	output, err := seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if strings.Contains(output, "offline") {
+		t.Errorf("did not expect offline marker online, got '%s'", output)
+	}
+
+	s.RateLimits.Offline = true
+	output, err = seg.Render(s, cfg)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if !strings.Contains(output, "offline") {
+		t.Errorf("expected offline marker, got '%s'", output)
+	}
+}
@@ -10,6 +10,11 @@ type Segment interface {
 	ID() string
 	Render(s *state.State, cfg *config.Config) (string, error)
 	Enabled(cfg *config.Config) bool
+	// Priority ranks the segment when a line is too wide for the terminal:
+	// the lowest priorities are shrunk, then dropped, first
+	Priority() int
+	// Compact is a shorter rendering for narrow terminals, "" to drop instead
+	Compact(s *state.State, cfg *config.Config) (string, error)
 }
 
 // All returns all available segments in display order
@@ -17,13 +22,18 @@ func All() []Segment {
 	return []Segment{
 		&ModelSegment{},
 		&ContextSegment{},
+		&CompactionSegment{},
+		&CacheSegment{},
 		&GitSegment{},
 		&CostSegment{},
+		&DurationSegment{},
+		&BudgetSegment{},
 		&ToolsSegment{},
 		&TasksSegment{},
 		&AgentSegment{},
 		&FiveHourSegment{},
 		&RateLimitSegment{},
+		&LimitsSegment{},
 	}
 }
 
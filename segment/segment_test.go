@@ -2,7 +2,9 @@ package segment
 
 import (
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/huyhandes/cc-hud-go/config"
 	"github.com/huyhandes/cc-hud-go/state"
 )
@@ -53,3 +55,58 @@ func TestRegistry(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactIsNarrower(t *testing.T) {
+	cfg := config.Default()
+	cfg.Budget.Daily = 10
+	cfg.Tables.ToolsThreshold = 999
+	cfg.Git.ShowLastCommit = true
+	s := state.New()
+	s.Model.Name = "Sonnet 4.5"
+	s.Context.TotalTokens = 200000
+	s.Context.UsedTokens = 50000
+	s.Context.Percentage = 25
+	s.Context.TotalInputTokens = 30000
+	s.Context.TotalOutputTokens = 10000
+	s.Context.CacheReadTokens = 5000
+	s.Context.CacheCreateTokens = 1000
+	s.Context.CacheHitRate = 80
+	s.Context.TurnHistory = []int{30000, 40000, 50000}
+	s.Git.Branch = "feature/width"
+	s.Git.DirtyFiles = 3
+	s.Git.Modified = 3
+	s.Git.Untracked = 2
+	committed := time.Now().Add(-time.Hour)
+	s.Git.LastCommitTime = &committed
+	s.Git.Repos = []state.RepoInfo{{Name: "docs", Branch: "main"}}
+	s.Cost.TotalUSD = 1.5
+	s.Cost.DurationMs = 60000
+	s.Budget.DailyUSD = 4
+	s.Tools.AppTools = map[string]int{"Read": 5, "Edit": 2}
+	s.Tasks.Pending = 2
+	s.Tasks.Completed = 1
+	s.Agents.ActiveAgent = "explorer"
+	s.Agents.TaskDesc = "Find the renderer"
+	s.RateLimits.FromOAuth = true
+	s.RateLimits.FiveHourPercent = 40
+	s.RateLimits.SevenDayPercent = 60
+	s.RateLimits.Buckets = map[string]state.RateLimitBucket{"seven_day_opus": {Percent: 90}}
+
+	for _, seg := range All() {
+		full, err := seg.Render(s, cfg)
+		if err != nil {
+			t.Fatalf("segment %s render failed: %v", seg.ID(), err)
+		}
+		compact, err := seg.Compact(s, cfg)
+		if err != nil {
+			t.Fatalf("segment %s compact failed: %v", seg.ID(), err)
+		}
+		if full == "" {
+			t.Errorf("segment %s rendered nothing; populate the test state", seg.ID())
+			continue
+		}
+		if lipgloss.Width(compact) > lipgloss.Width(full) {
+			t.Errorf("segment %s compact %q is wider than %q", seg.ID(), compact, full)
+		}
+	}
+}
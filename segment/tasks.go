@@ -5,6 +5,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -39,6 +40,20 @@ func (t *TasksSegment) Render(s *state.State, cfg *config.Config) (string, error
 	return t.renderInline(s, cfg)
 }
 
+func (t *TasksSegment) Priority() int {
+	return 30
+}
+
+// Compact replaces the dashboard with a single line, "📋 3/7"
+func (t *TasksSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	total := t.getTotalCount(s)
+	if total == 0 {
+		return "", nil
+	}
+	tasksStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInfo)
+	return tasksStyle.Render(icons.Label("tasks", fmt.Sprintf("%d/%d", s.Tasks.Completed, total))), nil
+}
+
 func (t *TasksSegment) renderInline(s *state.State, cfg *config.Config) (string, error) {
 
 	// Define colors
@@ -77,23 +92,23 @@ func (t *TasksSegment) renderInline(s *state.State, cfg *config.Config) (string,
 		Width(4)
 
 	// Build each row
-	header := headerStyle.Render("📋 Tasks Dashboard")
+	header := headerStyle.Render(icons.Label("tasks", "Tasks Dashboard"))
 
 	pendingRow := lipgloss.JoinHorizontal(
 		lipgloss.Top,
-		labelStyle.Render("  ⏳ Todo"),
+		labelStyle.Render("  "+icons.Label("pending", "Todo")),
 		pendingStyle.Render(fmt.Sprintf("%d", s.Tasks.Pending)),
 	)
 
 	progressRow := lipgloss.JoinHorizontal(
 		lipgloss.Top,
-		labelStyle.Render("  🔄 In Progress"),
+		labelStyle.Render("  "+icons.Label("inProgress", "In Progress")),
 		progressStyle.Render(fmt.Sprintf("%d", s.Tasks.InProgress)),
 	)
 
 	completedRow := lipgloss.JoinHorizontal(
 		lipgloss.Top,
-		labelStyle.Render("  ✅ Completed"),
+		labelStyle.Render("  "+icons.Label("completed", "Completed")),
 		completedStyle.Render(fmt.Sprintf("%d", s.Tasks.Completed)),
 	)
 
@@ -133,7 +148,7 @@ func (t *TasksSegment) renderTable(s *state.State, cfg *config.Config) (string,
 	for _, task := range s.Tasks.Details {
 		if task.Status == "pending" {
 			subject := t.truncate(task.Subject, 50)
-			rows = append(rows, []string{subject, "⏳ Pending"})
+			rows = append(rows, []string{subject, icons.Label("pending", "Pending")})
 		}
 	}
 
@@ -141,7 +156,7 @@ func (t *TasksSegment) renderTable(s *state.State, cfg *config.Config) (string,
 	for _, task := range s.Tasks.Details {
 		if task.Status == "in_progress" {
 			subject := t.truncate(task.Subject, 50)
-			rows = append(rows, []string{subject, "🔄 Active"})
+			rows = append(rows, []string{subject, icons.Label("inProgress", "Active")})
 		}
 	}
 
@@ -160,7 +175,7 @@ func (t *TasksSegment) renderTable(s *state.State, cfg *config.Config) (string,
 	}
 	for i := startIdx; i < len(completedTasks); i++ {
 		subject := t.truncate(completedTasks[i].Subject, 50)
-		rows = append(rows, []string{subject, "✅ Done"})
+		rows = append(rows, []string{subject, icons.Label("completed", "Done")})
 	}
 
 	return style.RenderTable(headers, rows), nil
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/huyhandes/cc-hud-go/config"
+	"github.com/huyhandes/cc-hud-go/icons"
 	"github.com/huyhandes/cc-hud-go/state"
 	"github.com/huyhandes/cc-hud-go/style"
 )
@@ -36,6 +37,20 @@ func (t *ToolsSegment) Render(s *state.State, cfg *config.Config) (string, error
 	return t.renderInline(s, cfg)
 }
 
+func (t *ToolsSegment) Priority() int {
+	return 10
+}
+
+// Compact replaces the boxed breakdown with the total, "🔧 42"
+func (t *ToolsSegment) Compact(s *state.State, cfg *config.Config) (string, error) {
+	toolCount := t.getTotalCount(s)
+	if toolCount == 0 {
+		return "", nil
+	}
+	toolsMainStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInfo)
+	return toolsMainStyle.Render(icons.Label("tools", fmt.Sprintf("%d", toolCount))), nil
+}
+
 func (t *ToolsSegment) getTotalCount(s *state.State) int {
 	total := 0
 
@@ -65,13 +80,11 @@ func (t *ToolsSegment) renderInline(s *state.State, cfg *config.Config) (string,
 
 	// Simple inline display if not grouped
 	if !cfg.Tools.GroupByCategory {
-		icon := "🔧"
 		toolsMainStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInfo)
-		return toolsMainStyle.Render(fmt.Sprintf("%s %d", icon, toolCount)), nil
+		return toolsMainStyle.Render(icons.Label("tools", fmt.Sprintf("%d", toolCount))), nil
 	}
 
 	// Enhanced lipgloss display when grouped by category
-	icon := "🔧"
 	appTotal := 0
 	for _, count := range s.Tools.AppTools {
 		appTotal += count
@@ -114,7 +127,7 @@ func (t *ToolsSegment) renderInline(s *state.State, cfg *config.Config) (string,
 		Width(6)
 
 	// Build header
-	header := headerStyle.Render(fmt.Sprintf("%s Tool Usage (%d)", icon, toolCount))
+	header := headerStyle.Render(icons.Label("tools", fmt.Sprintf("Tool Usage (%d)", toolCount)))
 
 	// Build rows for each category
 	var rows []string
@@ -122,7 +135,7 @@ func (t *ToolsSegment) renderInline(s *state.State, cfg *config.Config) (string,
 	if appTotal > 0 {
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			labelStyle.Render("  📦 App"),
+			labelStyle.Render("  "+icons.Label("app", "App")),
 			countStyle.Foreground(appColor).Render(fmt.Sprintf("%d", appTotal)),
 		)
 		rows = append(rows, row)
@@ -131,7 +144,7 @@ func (t *ToolsSegment) renderInline(s *state.State, cfg *config.Config) (string,
 	if mcpTotal > 0 && cfg.Tools.ShowMCP {
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			labelStyle.Render("  🔌 MCP"),
+			labelStyle.Render("  "+icons.Label("mcp", "MCP")),
 			countStyle.Foreground(mcpColor).Render(fmt.Sprintf("%d", mcpTotal)),
 		)
 		rows = append(rows, row)
@@ -140,7 +153,7 @@ func (t *ToolsSegment) renderInline(s *state.State, cfg *config.Config) (string,
 	if skillsTotal > 0 && cfg.Tools.ShowSkills {
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			labelStyle.Render("  ⚡ Skills"),
+			labelStyle.Render("  "+icons.Label("skills", "Skills")),
 			countStyle.Foreground(skillsColor).Render(fmt.Sprintf("%d", skillsTotal)),
 		)
 		rows = append(rows, row)
@@ -149,7 +162,7 @@ func (t *ToolsSegment) renderInline(s *state.State, cfg *config.Config) (string,
 	if customTotal > 0 {
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			labelStyle.Render("  🎨 Custom"),
+			labelStyle.Render("  "+icons.Label("custom", "Custom")),
 			countStyle.Foreground(customColor).Render(fmt.Sprintf("%d", customTotal)),
 		)
 		rows = append(rows, row)
@@ -157,9 +170,8 @@ func (t *ToolsSegment) renderInline(s *state.State, cfg *config.Config) (string,
 
 	// If no categories to show, just show total
 	if len(rows) == 0 {
-		icon := "🔧"
 		toolsMainStyle := style.GetRenderer().NewStyle().Foreground(style.ColorInfo)
-		return toolsMainStyle.Render(fmt.Sprintf("%s %d", icon, toolCount)), nil
+		return toolsMainStyle.Render(icons.Label("tools", fmt.Sprintf("%d", toolCount))), nil
 	}
 
 	// Combine header and rows
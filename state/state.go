@@ -1,104 +1,254 @@
 package state
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // State holds all current session data
 type State struct {
-	Model      ModelInfo
-	Context    ContextInfo
-	RateLimits RateLimitInfo
-	Git        GitInfo
-	Tools      ToolsState
-	Agents     AgentInfo
-	Tasks      TaskInfo
-	Session    SessionInfo
-	Cost       CostInfo
+	Model      ModelInfo     `json:"model"`
+	Context    ContextInfo   `json:"context"`
+	RateLimits RateLimitInfo `json:"rateLimits"`
+	Git        GitInfo       `json:"git"`
+	Tools      ToolsState    `json:"tools"`
+	Agents     AgentInfo     `json:"agents"`
+	Tasks      TaskInfo      `json:"tasks"`
+	Session    SessionInfo   `json:"session"`
+	Cost       CostInfo      `json:"cost"`
+	Budget     BudgetInfo    `json:"budget"`
 }
 
 type ModelInfo struct {
-	Name     string
-	PlanType string
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	PlanType string `json:"planType"`
 }
 
 type ContextInfo struct {
-	UsedTokens         int
-	TotalTokens        int
-	Percentage         float64
-	TotalInputTokens   int
-	TotalOutputTokens  int
-	CacheReadTokens    int
-	CacheCreateTokens  int
-	CurrentInputTokens int
+	UsedTokens         int     `json:"usedTokens"`
+	TotalTokens        int     `json:"totalTokens"`
+	Percentage         float64 `json:"percentage"`
+	TotalInputTokens   int     `json:"totalInputTokens"`
+	TotalOutputTokens  int     `json:"totalOutputTokens"`
+	CacheReadTokens    int     `json:"cacheReadTokens"`
+	CacheCreateTokens  int     `json:"cacheCreateTokens"`
+	CurrentInputTokens int     `json:"currentInputTokens"`
+	CacheHitRate       float64 `json:"cacheHitRate"` // Percent of current input served from cache
+	TurnHistory        []int   `json:"turnHistory"`  // Context tokens at the end of each user turn
+}
+
+// forecastWindow is how many recent turns are used to estimate context growth
+const forecastWindow = 6
+
+// TurnsUntil estimates how many more user turns fit before the context
+// reaches limitTokens, based on the average growth of recent turns.
+// Returns false when there is not enough history to make a forecast.
+func (c ContextInfo) TurnsUntil(limitTokens int) (int, bool) {
+	// Only consider turns since the last compaction (context shrank)
+	history := c.TurnHistory
+	for i := len(history) - 1; i > 0; i-- {
+		if history[i] < history[i-1] {
+			history = history[i:]
+			break
+		}
+	}
+	if len(history) > forecastWindow {
+		history = history[len(history)-forecastWindow:]
+	}
+	if len(history) < 2 {
+		return 0, false
+	}
+
+	growth := float64(history[len(history)-1]-history[0]) / float64(len(history)-1)
+	if growth <= 0 {
+		return 0, false
+	}
+
+	current := c.UsedTokens
+	if current == 0 {
+		current = history[len(history)-1]
+	}
+
+	remaining := limitTokens - current
+	if remaining <= 0 {
+		return 0, true
+	}
+
+	return int(float64(remaining) / growth), true
 }
 
 type RateLimitInfo struct {
-	HourlyUsed       int
-	HourlyTotal      int
-	SevenDayUsed     int
-	SevenDayTotal    int
-	FiveHourPercent  float64 // From OAuth API
-	SevenDayPercent  float64 // From OAuth API
-	FiveHourResetsAt string  // ISO 8601 timestamp
-	SevenDayResetsAt string  // ISO 8601 timestamp
+	HourlyUsed       int                        `json:"hourlyUsed"`
+	HourlyTotal      int                        `json:"hourlyTotal"`
+	SevenDayUsed     int                        `json:"sevenDayUsed"`
+	SevenDayTotal    int                        `json:"sevenDayTotal"`
+	FiveHourPercent  float64                    `json:"fiveHourPercent"`  // From OAuth API
+	SevenDayPercent  float64                    `json:"sevenDayPercent"`  // From OAuth API
+	FiveHourResetsAt string                     `json:"fiveHourResetsAt"` // ISO 8601 timestamp
+	SevenDayResetsAt string                     `json:"sevenDayResetsAt"` // ISO 8601 timestamp
+	FromOAuth        bool                       `json:"fromOAuth"`        // Percentages came from the OAuth API (0% is a real value)
+	Stale            bool                       `json:"stale"`            // OAuth data is a cached value that could not be refreshed
+	Offline          bool                       `json:"offline"`          // Offline mode: cached OAuth data, network not contacted
+	FiveHourLimitAt  string                     `json:"fiveHourLimitAt"`  // Projected 5h exhaustion (ISO 8601), empty without a projection
+	Buckets          map[string]RateLimitBucket `json:"buckets"`          // Every OAuth bucket by name (e.g. "seven_day_opus")
+	ExtraUsage       ExtraUsageInfo             `json:"extraUsage"`
+}
+
+// RateLimitBucket is a single rate limit window from the OAuth API
+type RateLimitBucket struct {
+	Percent  float64 `json:"percent"`
+	ResetsAt string  `json:"resetsAt"` // ISO 8601 timestamp, empty if unknown
+}
+
+// ExtraUsageInfo describes pay-as-you-go usage beyond the plan limits
+type ExtraUsageInfo struct {
+	Enabled bool    `json:"enabled"`
+	Percent float64 `json:"percent"`
 }
 
 type GitInfo struct {
-	Branch     string
-	DirtyFiles int
-	Ahead      int
-	Behind     int
-	Added      int
-	Modified   int
-	Deleted    int
+	Branch     string `json:"branch"`
+	DirtyFiles int    `json:"dirtyFiles"`
+	Ahead      int    `json:"ahead"`
+	Behind     int    `json:"behind"`
+	Added      int    `json:"added"`
+	Modified   int    `json:"modified"`
+	Deleted    int    `json:"deleted"`
+	Renamed    int    `json:"renamed"`
+	Untracked  int    `json:"untracked"`
+	Conflicts  int    `json:"conflicts"`
+	Stashes    int    `json:"stashes"`
+	Detached   bool   `json:"detached"`  // HEAD is not on a branch
+	Head       string `json:"head"`      // Short SHA of HEAD
+	Operation  string `json:"operation"` // Multi-step operation in progress (e.g. "rebase", "merge")
+	Tag        string `json:"tag"`       // Nearest tag reachable from HEAD
+
+	Unstaged    LineStats `json:"unstaged"`    // Worktree vs index
+	Staged      LineStats `json:"staged"`      // Index vs HEAD
+	Total       LineStats `json:"total"`       // Worktree vs HEAD
+	Base        string    `json:"base"`        // Branch compared against, empty when not compared
+	BaseCommits int       `json:"baseCommits"` // Commits on HEAD since the merge-base with Base
+	BaseLines   LineStats `json:"baseLines"`   // Lines changed between the merge-base and HEAD
+
+	Worktree string     `json:"worktree"` // Linked worktree name, empty in the main worktree
+	Repos    []RepoInfo `json:"repos"`    // Extra repositories, in configured order
+
+	SessionCommits []SessionCommit `json:"sessionCommits"` // Commits created during this session, newest first
+
+	LastCommitTime    *time.Time `json:"lastCommitTime,omitempty"` // Committer time of HEAD, nil when not read
+	LastCommitAuthor  string     `json:"lastCommitAuthor"`
+	LastCommitSubject string     `json:"lastCommitSubject"`
+}
+
+// SessionCommit is a commit created during the session
+type SessionCommit struct {
+	ID      string    `json:"id"`
+	Subject string    `json:"subject"`
+	Lines   LineStats `json:"lines"`
+}
+
+// SessionLines totals the lines changed by the session's commits
+func (g GitInfo) SessionLines() LineStats {
+	var total LineStats
+	for _, commit := range g.SessionCommits {
+		total.Added += commit.Lines.Added
+		total.Removed += commit.Lines.Removed
+	}
+	return total
+}
+
+// RepoInfo is the compact view of an extra repository
+type RepoInfo struct {
+	Name       string `json:"name"`
+	Branch     string `json:"branch"`
+	DirtyFiles int    `json:"dirtyFiles"`
+	TimedOut   bool   `json:"timedOut"` // The last collection gave up; values may be older
+}
+
+// LineStats counts lines added and removed
+type LineStats struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// IsZero reports whether no lines changed
+func (l LineStats) IsZero() bool {
+	return l.Added == 0 && l.Removed == 0
 }
 
 type ToolsState struct {
-	AppTools      map[string]int
-	InternalTools map[string]int
-	CustomTools   map[string]int
-	MCPTools      map[MCPServer]map[string]int
-	Skills        map[string]SkillUsage
+	AppTools      map[string]int               `json:"appTools"`
+	InternalTools map[string]int               `json:"internalTools"`
+	CustomTools   map[string]int               `json:"customTools"`
+	MCPTools      map[MCPServer]map[string]int `json:"mcpTools"`
+	Skills        map[string]SkillUsage        `json:"skills"`
 }
 
 type MCPServer struct {
-	Name string
-	Type string
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// MarshalText encodes the server as "type:name", so MCPTools can be a JSON object
+func (m MCPServer) MarshalText() ([]byte, error) {
+	return []byte(m.Type + ":" + m.Name), nil
+}
+
+// UnmarshalText decodes a server encoded by MarshalText
+func (m *MCPServer) UnmarshalText(text []byte) error {
+	typ, name, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("invalid MCP server %q: want type:name", text)
+	}
+	m.Type, m.Name = typ, name
+	return nil
 }
 
 type SkillUsage struct {
-	Count int
+	Count int `json:"count"`
 }
 
 type AgentInfo struct {
-	ActiveAgent string
-	TaskDesc    string
+	ActiveAgent string `json:"activeAgent"`
+	TaskDesc    string `json:"taskDesc"`
 }
 
 type Task struct {
-	Subject string
-	Status  string
+	Subject string `json:"subject"`
+	Status  string `json:"status"`
 }
 
 type TaskInfo struct {
-	Pending    int
-	InProgress int
-	Completed  int
-	Details    []Task
+	Pending    int    `json:"pending"`
+	InProgress int    `json:"inProgress"`
+	Completed  int    `json:"completed"`
+	Details    []Task `json:"details"`
 }
 
 type SessionInfo struct {
-	ID             string
-	TranscriptPath string
-	StartTime      time.Time
-	Duration       time.Duration
+	ID             string        `json:"id"`
+	TranscriptPath string        `json:"transcriptPath"`
+	WorkDir        string        `json:"workDir"`    // Directory Claude is working in (workspace.current_dir)
+	ProjectDir     string        `json:"projectDir"` // Directory Claude Code was started in (workspace.project_dir)
+	StartTime      time.Time     `json:"-"`          // When this process started, not the session
+	Duration       time.Duration `json:"-"`          // Since StartTime; the session length is Cost.DurationMs
 }
 
 type CostInfo struct {
-	TotalUSD      float64
-	DurationMs    int64
-	APIDurationMs int64
-	LinesAdded    int
-	LinesRemoved  int
+	TotalUSD      float64 `json:"totalUSD"`
+	DurationMs    int64   `json:"durationMs"`
+	APIDurationMs int64   `json:"apiDurationMs"`
+	LinesAdded    int     `json:"linesAdded"`
+	LinesRemoved  int     `json:"linesRemoved"`
+}
+
+// BudgetInfo holds spend aggregated across all sessions
+type BudgetInfo struct {
+	DailyUSD   float64 `json:"dailyUSD"`
+	WeeklyUSD  float64 `json:"weeklyUSD"`
+	MonthlyUSD float64 `json:"monthlyUSD"`
 }
 
 // New creates a new State with initialized maps
@@ -126,4 +276,10 @@ func (s *State) UpdateDerived() {
 	if s.Context.TotalTokens > 0 {
 		s.Context.Percentage = float64(s.Context.UsedTokens) / float64(s.Context.TotalTokens) * 100.0
 	}
+
+	// Update cache efficiency: read / (read + write + uncached input)
+	cacheable := s.Context.CacheReadTokens + s.Context.CacheCreateTokens + s.Context.CurrentInputTokens
+	if cacheable > 0 {
+		s.Context.CacheHitRate = float64(s.Context.CacheReadTokens) / float64(cacheable) * 100.0
+	}
 }
@@ -46,3 +46,44 @@ func TestUpdateDerived(t *testing.T) {
 		t.Errorf("expected Percentage 50.0, got %f", s.Context.Percentage)
 	}
 }
+
+func TestUpdateDerivedCacheHitRate(t *testing.T) {
+	s := New()
+	s.Context.CacheReadTokens = 60
+	s.Context.CacheCreateTokens = 30
+	s.Context.CurrentInputTokens = 10
+	s.UpdateDerived()
+
+	if s.Context.CacheHitRate != 60.0 {
+		t.Errorf("expected CacheHitRate 60.0, got %f", s.Context.CacheHitRate)
+	}
+}
+
+func TestTurnsUntil(t *testing.T) {
+	tests := []struct {
+		name      string
+		history   []int
+		used      int
+		limit     int
+		wantTurns int
+		wantOK    bool
+	}{
+		{"not enough history", []int{10000}, 10000, 160000, 0, false},
+		{"steady growth", []int{10000, 20000, 30000, 40000}, 40000, 160000, 12, true},
+		{"uses live usage", []int{10000, 20000, 30000}, 35000, 160000, 12, true},
+		{"no growth", []int{30000, 30000, 30000}, 30000, 160000, 0, false},
+		{"past threshold", []int{100000, 150000}, 170000, 160000, 0, true},
+		{"ignores turns before compaction", []int{100000, 150000, 20000, 25000, 30000}, 30000, 160000, 26, true},
+		{"recent window only", []int{10000, 11000, 12000, 13000, 14000, 15000, 20000, 40000, 60000, 80000, 100000, 120000}, 120000, 160000, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ContextInfo{TurnHistory: tt.history, UsedTokens: tt.used}
+			turns, ok := c.TurnsUntil(tt.limit)
+			if ok != tt.wantOK || turns != tt.wantTurns {
+				t.Errorf("TurnsUntil(%d) = (%d, %v), want (%d, %v)", tt.limit, turns, ok, tt.wantTurns, tt.wantOK)
+			}
+		})
+	}
+}
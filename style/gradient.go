@@ -5,11 +5,14 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // RenderGradientBar renders a static gradient progress bar
 // The gradient is always green -> yellow -> orange -> red (0-100%)
 // Only the filled portion (based on percentage) is displayed
+// 256 color terminals get the nearest palette colors, 16 color terminals step
+// green -> yellow -> red, and without colors only the characters remain
 func RenderGradientBar(percentage float64, width int) string {
 	if width <= 0 {
 		width = 10
@@ -26,12 +29,20 @@ func RenderGradientBar(percentage float64, width int) string {
 		filled = width
 	}
 
+	// Without colors the characters alone show the fill
+	if renderer.ColorProfile() == termenv.Ascii {
+		return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	}
+
 	segments := make([]string, 0, width)
 
 	for i := 0; i < width; i++ {
 		if i < filled {
 			positionPercent := (float64(i) / float64(width)) * 100
 			color := getStaticGradientColor(positionPercent)
+			if renderer.ColorProfile() == termenv.ANSI {
+				color = getANSIGradientColor(positionPercent)
+			}
 			segments = append(segments, renderer.NewStyle().Foreground(color).Render("█"))
 		} else {
 			segments = append(segments, renderer.NewStyle().Foreground(ColorMuted).Render("░"))
@@ -41,6 +52,20 @@ func RenderGradientBar(percentage float64, width int) string {
 	return strings.Join(segments, "")
 }
 
+// RenderMutedBar renders a progress bar entirely in the muted color,
+// used for values that may be out of date
+func RenderMutedBar(percentage float64, width int) string {
+	if width <= 0 {
+		width = 10
+	}
+	percentage = max(0, min(100, percentage))
+
+	filled := int(percentage / 100 * float64(width))
+	mutedStyle := renderer.NewStyle().Foreground(ColorMuted)
+
+	return mutedStyle.Render(strings.Repeat("█", filled) + strings.Repeat("░", width-filled))
+}
+
 // getStaticGradientColor returns a color from the static gradient (0-100%)
 // Gradient: green (0%) -> yellow (50%) -> orange (75%) -> red (100%)
 func getStaticGradientColor(position float64) lipgloss.Color {
@@ -66,6 +91,18 @@ func getStaticGradientColor(position float64) lipgloss.Color {
 	return lipgloss.Color(formatRGB(r, g, b))
 }
 
+// getANSIGradientColor steps through the 16 color palette, where the hex
+// gradient would collapse to one or two nearest colors
+func getANSIGradientColor(position float64) lipgloss.Color {
+	if position < 50 {
+		return lipgloss.Color("10") // Bright green
+	}
+	if position < 75 {
+		return lipgloss.Color("11") // Bright yellow
+	}
+	return lipgloss.Color("9") // Bright red
+}
+
 func lerp(start, end uint8, t float64) uint8 {
 	return uint8(float64(start) + (float64(end)-float64(start))*t)
 }
@@ -0,0 +1,63 @@
+package style
+
+import (
+	"io"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Color modes accepted by the --color flag
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// profiles maps the colorProfile config option to a color profile
+var profiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"256":       termenv.ANSI256,
+	"16":        termenv.ANSI,
+	"none":      termenv.Ascii,
+}
+
+// DetectProfile picks the color profile from the --color mode, the
+// colorProfile config option ("" or "auto" to detect) and the environment.
+// Claude Code pipes stdout, so the terminal is judged by TERM and COLORTERM
+// rather than probed. Like the flag, a configured profile overrides NO_COLOR.
+func DetectProfile(mode, configured string, getenv func(string) string) termenv.Profile {
+	if mode == ColorNever {
+		return termenv.Ascii
+	}
+	if p, ok := profiles[configured]; ok && (mode != ColorAlways || p != termenv.Ascii) {
+		return p
+	}
+
+	out := termenv.NewOutput(io.Discard, termenv.WithTTY(true), termenv.WithEnvironment(environ(getenv)))
+	if mode == ColorAlways {
+		if p := out.ColorProfile(); p != termenv.Ascii {
+			return p
+		}
+		return termenv.TrueColor
+	}
+	return out.EnvColorProfile()
+}
+
+// SetColorProfile sets the color profile for everything rendered, including
+// styles built on lipgloss's default renderer
+func SetColorProfile(p termenv.Profile) {
+	renderer.SetColorProfile(p)
+	lipgloss.SetColorProfile(p)
+}
+
+// environ adapts a getenv function to termenv.Environ
+type environ func(string) string
+
+func (e environ) Environ() []string {
+	return nil
+}
+
+func (e environ) Getenv(key string) string {
+	return e(key)
+}
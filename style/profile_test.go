@@ -0,0 +1,65 @@
+package style
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestDetectProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		configured string
+		env        map[string]string
+		want       termenv.Profile
+	}{
+		{"truecolor terminal", ColorAuto, "", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, termenv.TrueColor},
+		{"256 color terminal", ColorAuto, "", map[string]string{"TERM": "xterm-256color"}, termenv.ANSI256},
+		{"tmux without RGB", ColorAuto, "", map[string]string{"TERM": "screen-256color"}, termenv.ANSI256},
+		{"16 color terminal", ColorAuto, "", map[string]string{"TERM": "xterm"}, termenv.ANSI},
+		{"dumb terminal", ColorAuto, "", map[string]string{"TERM": "dumb"}, termenv.Ascii},
+		{"no color", ColorAuto, "", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor", "NO_COLOR": "1"}, termenv.Ascii},
+		{"configured profile", ColorAuto, "256", map[string]string{"COLORTERM": "truecolor"}, termenv.ANSI256},
+		{"configured auto", ColorAuto, "auto", map[string]string{"TERM": "xterm"}, termenv.ANSI},
+		{"config overrides no color", ColorAuto, "16", map[string]string{"NO_COLOR": "1"}, termenv.ANSI},
+		{"never", ColorNever, "truecolor", map[string]string{"COLORTERM": "truecolor"}, termenv.Ascii},
+		{"always overrides no color", ColorAlways, "", map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, termenv.ANSI256},
+		{"always without a terminal", ColorAlways, "", map[string]string{}, termenv.TrueColor},
+		{"always overrides configured none", ColorAlways, "none", map[string]string{"TERM": "xterm"}, termenv.ANSI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := DetectProfile(tt.mode, tt.configured, getenv); got != tt.want {
+				t.Errorf("DetectProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderGradientBarProfiles(t *testing.T) {
+	Init(&mockTheme{})
+	defer SetColorProfile(termenv.TrueColor)
+
+	SetColorProfile(termenv.Ascii)
+	if got := RenderGradientBar(50, 4); got != "██░░" {
+		t.Errorf("expected plain characters without colors, got %q", got)
+	}
+
+	SetColorProfile(termenv.ANSI)
+	bar := RenderGradientBar(100, 4)
+	if strings.Contains(bar, "38;2;") || strings.Contains(bar, "38;5;") {
+		t.Errorf("expected only 16 color escapes, got %q", bar)
+	}
+	if !strings.Contains(bar, "\x1b[92m") || !strings.Contains(bar, "\x1b[91m") {
+		t.Errorf("expected the bar to step from green to red, got %q", bar)
+	}
+
+	SetColorProfile(termenv.ANSI256)
+	if bar := RenderGradientBar(100, 4); strings.Contains(bar, "38;2;") || !strings.Contains(bar, "38;5;") {
+		t.Errorf("expected 256 color escapes, got %q", bar)
+	}
+}
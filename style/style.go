@@ -2,6 +2,7 @@ package style
 
 import (
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/huyhandes/cc-hud-go/theme"
@@ -25,6 +26,8 @@ var (
 	ColorMuted      lipgloss.Color
 	ColorBright     lipgloss.Color
 	ColorInfo       lipgloss.Color
+	ColorSurface    lipgloss.Color // Segment backgrounds (powerline, capsules)
+	ColorSurfaceAlt lipgloss.Color
 
 	// Pre-configured styles
 	ModelStyle     lipgloss.Style
@@ -61,6 +64,8 @@ func Init(th theme.Theme) {
 	ColorMuted = th.GetColor("muted")
 	ColorBright = th.GetColor("bright")
 	ColorInfo = th.GetColor("info")
+	ColorSurface = th.GetColor("surface")
+	ColorSurfaceAlt = th.GetColor("surfaceAlt")
 
 	ModelStyle = renderer.NewStyle().Foreground(ColorPrimary).Bold(true)
 	ContextStyle = renderer.NewStyle().Foreground(ColorInfo)
@@ -85,6 +90,19 @@ func Separator() string {
 	return SeparatorStyle.Render("│")
 }
 
+// WithBackground paints already styled text on bg, reapplying the background
+// after every reset inside it
+func WithBackground(text string, bg lipgloss.Color) string {
+	seq := renderer.ColorProfile().Color(string(bg)).Sequence(true)
+	if seq == "" {
+		return text
+	}
+
+	on := termenv.CSI + seq + "m"
+	reset := termenv.CSI + termenv.ResetSeq + "m"
+	return on + strings.ReplaceAll(text, reset, reset+on) + reset
+}
+
 // Icon renders a styled icon
 func Icon(icon string, s lipgloss.Style) string {
 	return s.Render(icon)
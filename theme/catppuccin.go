@@ -34,9 +34,11 @@ func (m *Macchiato) GetColor(semantic string) lipgloss.Color {
 		"accent":    "#f5a97f", // Peach
 
 		// Utility colors
-		"muted":  "#5b6078", // Overlay0
-		"bright": "#cad3f5", // Text
-		"info":   "#8bd5ca", // Teal
+		"muted":      "#5b6078", // Overlay0
+		"bright":     "#cad3f5", // Text
+		"info":       "#8bd5ca", // Teal
+		"surface":    "#363a4f", // Surface0
+		"surfaceAlt": "#494d64", // Surface1
 	}
 
 	if color, ok := colors[semantic]; ok {
@@ -71,6 +73,8 @@ func (m *Mocha) GetColor(semantic string) lipgloss.Color {
 		"muted":      "#585b70", // Overlay0
 		"bright":     "#cdd6f4", // Text
 		"info":       "#94e2d5", // Teal
+		"surface":    "#313244", // Surface0
+		"surfaceAlt": "#45475a", // Surface1
 	}
 
 	if color, ok := colors[semantic]; ok {
@@ -105,6 +109,8 @@ func (f *Frappe) GetColor(semantic string) lipgloss.Color {
 		"muted":      "#51576d", // Overlay0
 		"bright":     "#c6d0f5", // Text
 		"info":       "#81c8be", // Teal
+		"surface":    "#414559", // Surface0
+		"surfaceAlt": "#51576d", // Surface1
 	}
 
 	if color, ok := colors[semantic]; ok {
@@ -139,6 +145,8 @@ func (l *Latte) GetColor(semantic string) lipgloss.Color {
 		"muted":      "#9ca0b0", // Overlay0
 		"bright":     "#4c4f69", // Text
 		"info":       "#179299", // Teal
+		"surface":    "#ccd0da", // Surface0
+		"surfaceAlt": "#bcc0cc", // Surface1
 	}
 
 	if color, ok := colors[semantic]; ok {